@@ -66,11 +66,11 @@ func worker(ctx context.Context, id int, producer kafka.Producer) {
 			RequestID:    "REQ7890",
 			Currency:     "RUB",
 			Provider:     "bank_card",
-			Amount:       3500,
+			Amount:       3300,
 			PaymentDt:    time.Now().Unix(),
 			Bank:         "Tinkoff",
 			DeliveryCost: 300,
-			GoodsTotal:   3200,
+			GoodsTotal:   3000,
 			CustomFee:    0,
 		},
 
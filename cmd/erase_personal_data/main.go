@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/app"
+	"wb-tech-test-assignment/internal/config"
+	"wb-tech-test-assignment/internal/model"
+	"wb-tech-test-assignment/pkg/logger"
+)
+
+// Erases personal data of a customer or of an order and prints the audit entry, for example:
+//
+//	go run ./cmd/erase_personal_data -config ./config/config.template.yml -customer-id test -requested-by dpo@example.com
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var (
+		customerID  = flag.String("customer-id", "", "Erase all orders of the customer")
+		orderUID    = flag.String("order-uid", "", "Erase the order")
+		requestedBy = flag.String("requested-by", "", "Who requested the erasure, stored in the audit entry")
+		reason      = flag.String("reason", "", "Reason of the erasure, for example the ticket of the deletion request")
+	)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Parses the flags above as well.
+	cfg := config.MustLoadConfig()
+
+	req := model.ErasureRequest{
+		Subject:     model.ErasureSubjectCustomer,
+		SubjectID:   *customerID,
+		RequestedBy: *requestedBy,
+		Reason:      *reason,
+	}
+
+	switch {
+	case (*customerID == "") == (*orderUID == ""):
+		return errors.New("exactly one of -customer-id and -order-uid is required")
+	case *orderUID != "":
+		req.Subject = model.ErasureSubjectOrder
+		req.SubjectID = *orderUID
+	}
+
+	log := logger.MustSetupLogger(&logger.Config{
+		Level:      cfg.Level,
+		FormatJSON: cfg.FormatJSON,
+		Rotation: logger.Rotation{
+			File:       cfg.Rotation.File,
+			MaxSize:    cfg.Rotation.MaxSize,
+			MaxBackups: cfg.Rotation.MaxBackups,
+			MaxAge:     cfg.Rotation.MaxAge,
+		},
+	})
+
+	defer func() {
+		_ = log.Sync()
+	}()
+
+	erasure, err := app.NewErasure(cfg, log)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := erasure.Close(); err != nil {
+			log.Warn("Failed to close erasure", zap.Error(err))
+		}
+	}()
+
+	result, err := erasure.Service.Erase(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(result)
+}
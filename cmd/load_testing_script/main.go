@@ -6,6 +6,8 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 
 	"wb-tech-test-assignment/internal/config"
@@ -17,6 +19,9 @@ func main() {
 	host := cfg.HTTPServer.Host
 	port := cfg.HTTPServer.Port
 
+	// Required if http_server.auth.enable is set.
+	apiKey := os.Getenv("API_KEY")
+
 	client := &http.Client{
 		Timeout: 5 * time.Second,
 		Transport: &http.Transport{
@@ -27,11 +32,32 @@ func main() {
 	}
 
 	for i := 0; i < 1000; i++ {
-		resp, err := client.Get(fmt.Sprintf("http://%s:%d/api/order/%d", host, port, i))
+		req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s:%d%s/v1/order/%d", host, port, cfg.HTTPServer.BasePath, i), nil)
+		if err != nil {
+			log.Fatalf("Failed to create request for order %d: %v", i, err)
+		}
+
+		if apiKey != "" {
+			req.Header.Set("X-API-Key", apiKey)
+		}
+
+		resp, err := client.Do(req)
 		if err != nil {
 			log.Fatalf("Failed to get order %d: %v", i, err)
 		}
 
+		// The server is rate limited, wait as asked and repeat the order.
+		if resp.StatusCode == http.StatusTooManyRequests {
+			_ = resp.Body.Close()
+
+			retryAfter, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+			time.Sleep(time.Duration(max(retryAfter, 1)) * time.Second)
+
+			i--
+
+			continue
+		}
+
 		body, err := io.ReadAll(resp.Body)
 		_ = resp.Body.Close()
 
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/model"
+	ordersv1 "wb-tech-test-assignment/pkg/api/orders/v1"
+)
+
+// MetadataAPIKey is the gRPC counterpart of the X-API-Key header of the HTTP API.
+const MetadataAPIKey = "x-api-key"
+
+// UnaryAuth authenticates calls of the order service with the same credentials and roles as the
+// HTTP API: the API key is taken from x-api-key or "authorization: Bearer <key>", bearer tokens
+// of three segments are verified as JWT. Health checks and reflection are not authenticated.
+func UnaryAuth(log *zap.Logger, authenticator *auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !protected(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := authenticate(ctx, log, authenticator, info.FullMethod)
+		if err != nil {
+			return nil, toStatus(log, err)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth is UnaryAuth for streaming calls.
+func StreamAuth(log *zap.Logger, authenticator *auth.Authenticator) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !protected(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		ctx, err := authenticate(stream.Context(), log, authenticator, info.FullMethod)
+		if err != nil {
+			return toStatus(log, err)
+		}
+
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func protected(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/"+ordersv1.OrderService_ServiceDesc.ServiceName+"/")
+}
+
+// authenticate puts the principal of the call into ctx. All methods of the order service read
+// orders, personal data is redacted by the handlers for principals without the permission.
+func authenticate(ctx context.Context, log *zap.Logger, authenticator *auth.Authenticator, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	principal := authenticator.Anonymous()
+
+	if key := first(md.Get(MetadataAPIKey)); key != "" {
+		p, ok := authenticator.APIKey(key)
+		if !ok {
+			log.Warn("Unknown API key", zap.String("method", method))
+
+			return nil, apperrors.ErrInvalidCredentials
+		}
+
+		principal = p
+	} else if token, ok := strings.CutPrefix(first(md.Get("authorization")), "Bearer "); ok {
+		p, err := authenticator.Bearer(token)
+		if err != nil {
+			log.Warn("Invalid bearer token", zap.Error(err), zap.String("method", method))
+
+			return nil, apperrors.ErrInvalidCredentials
+		}
+
+		principal = p
+	}
+
+	switch {
+	case principal.Can(auth.PermReadOrders):
+		return auth.WithPrincipal(ctx, principal), nil
+	case principal.Method == auth.MethodAnonymous:
+		return nil, apperrors.ErrUnauthorized
+	default:
+		return nil, apperrors.ErrForbidden
+	}
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// canSeePersonalData reports whether the caller may see personal data of customers, the others
+// get orders with the data replaced by model.RedactedValue, as in the HTTP API.
+func canSeePersonalData(ctx context.Context) bool {
+	return auth.FromContext(ctx).Can(auth.PermReadPersonalData)
+}
+
+func redactOrders(ctx context.Context, orders []model.Order) {
+	if canSeePersonalData(ctx) {
+		return
+	}
+
+	for i := range orders {
+		orders[i].RedactPersonalData()
+	}
+}
@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"wb-tech-test-assignment/internal/model"
+	ordersv1 "wb-tech-test-assignment/pkg/api/orders/v1"
+)
+
+func orderToProto(order model.Order) *ordersv1.Order {
+	pb := &ordersv1.Order{
+		OrderUid:    order.OrderUID,
+		TrackNumber: order.TrackNumber,
+		Entry:       order.Entry,
+		Delivery: &ordersv1.Delivery{
+			Name:    order.Delivery.Name,
+			Phone:   order.Delivery.Phone,
+			Zip:     order.Delivery.Zip,
+			City:    order.Delivery.City,
+			Address: order.Delivery.Address,
+			Region:  order.Delivery.Region,
+			Email:   order.Delivery.Email,
+		},
+		Payment: &ordersv1.Payment{
+			Transaction:  order.Payment.Transaction,
+			RequestId:    order.Payment.RequestID,
+			Currency:     order.Payment.Currency,
+			Provider:     order.Payment.Provider,
+			Amount:       int64(order.Payment.Amount),
+			PaymentDt:    order.Payment.PaymentDt,
+			Bank:         order.Payment.Bank,
+			DeliveryCost: int64(order.Payment.DeliveryCost),
+			GoodsTotal:   int64(order.Payment.GoodsTotal),
+			CustomFee:    int64(order.Payment.CustomFee),
+		},
+		Items:             make([]*ordersv1.Item, 0, len(order.Items)),
+		Locale:            order.Locale,
+		InternalSignature: order.InternalSignature,
+		CustomerId:        order.CustomerID,
+		DeliveryService:   order.DeliveryService,
+		Shardkey:          order.ShardKey,
+		SmId:              int64(order.SmID),
+		DateCreated:       timestamppb.New(order.DateCreated),
+		OofShard:          order.OofShard,
+	}
+
+	for _, item := range order.Items {
+		pb.Items = append(pb.Items, &ordersv1.Item{
+			ChrtId:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int64(item.Price),
+			Rid:         item.RID,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int64(item.TotalPrice),
+			NmId:        int64(item.NmID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		})
+	}
+
+	if order.Cancellation != nil {
+		pb.Cancellation = &ordersv1.Cancellation{
+			CancelledAt: timestamppb.New(order.Cancellation.CancelledAt),
+			Reason:      order.Cancellation.Reason,
+		}
+	}
+
+	if !order.UpdatedAt.IsZero() {
+		pb.UpdatedAt = timestamppb.New(order.UpdatedAt)
+	}
+
+	return pb
+}
+
+func ordersToProto(orders []model.Order) []*ordersv1.Order {
+	result := make([]*ordersv1.Order, 0, len(orders))
+	for _, order := range orders {
+		result = append(result, orderToProto(order))
+	}
+
+	return result
+}
@@ -0,0 +1,223 @@
+// Package handler implements the gRPC API on top of the order service.
+package handler
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/model"
+	"wb-tech-test-assignment/internal/service"
+	ordersv1 "wb-tech-test-assignment/pkg/api/orders/v1"
+)
+
+const defaultMaxBatchOrderUIDs = 1000
+
+type OrderService interface {
+	GetOrder(ctx context.Context, orderUID string) (model.Order, error)
+	LookupOrders(ctx context.Context, orderUIDs []string) (model.OrderLookup, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter) (model.OrderPage, error)
+	WatchOrders(ctx context.Context, filter service.WatchFilter, afterSequence uint64) service.OrderSubscription
+}
+
+type OrderServer struct {
+	ordersv1.UnimplementedOrderServiceServer
+
+	log          *zap.Logger
+	svc          OrderService
+	maxOrderUIDs int
+}
+
+func NewOrderServer(log *zap.Logger, svc OrderService, maxOrderUIDs int) *OrderServer {
+	if maxOrderUIDs <= 0 {
+		maxOrderUIDs = defaultMaxBatchOrderUIDs
+	}
+
+	return &OrderServer{
+		log:          log,
+		svc:          svc,
+		maxOrderUIDs: maxOrderUIDs,
+	}
+}
+
+func (s *OrderServer) GetOrder(ctx context.Context, req *ordersv1.GetOrderRequest) (*ordersv1.GetOrderResponse, error) {
+	if req.GetOrderUid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "order_uid is required")
+	}
+
+	order, err := s.svc.GetOrder(ctx, req.GetOrderUid())
+	if err != nil {
+		return nil, toStatus(s.log, err)
+	}
+
+	if !canSeePersonalData(ctx) {
+		order.RedactPersonalData()
+	}
+
+	return &ordersv1.GetOrderResponse{Order: orderToProto(order)}, nil
+}
+
+func (s *OrderServer) BatchGetOrders(ctx context.Context, req *ordersv1.BatchGetOrdersRequest) (*ordersv1.BatchGetOrdersResponse, error) {
+	if len(req.GetOrderUids()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "order_uids must not be empty")
+	}
+
+	if len(req.GetOrderUids()) > s.maxOrderUIDs {
+		return nil, status.Errorf(codes.InvalidArgument, "%d order_uids exceed the limit of %d", len(req.GetOrderUids()), s.maxOrderUIDs)
+	}
+
+	lookup, err := s.svc.LookupOrders(ctx, req.GetOrderUids())
+	if err != nil {
+		return nil, toStatus(s.log, err)
+	}
+
+	redactOrders(ctx, lookup.Orders)
+
+	return &ordersv1.BatchGetOrdersResponse{
+		Orders:  ordersToProto(lookup.Orders),
+		Missing: lookup.Missing,
+	}, nil
+}
+
+func (s *OrderServer) ListOrders(ctx context.Context, req *ordersv1.ListOrdersRequest) (*ordersv1.ListOrdersResponse, error) {
+	filter := model.OrderFilter{
+		CustomerID:      req.GetCustomerId(),
+		DeliveryService: req.GetDeliveryService(),
+		Locale:          req.GetLocale(),
+		Currency:        req.GetCurrency(),
+		Bank:            req.GetBank(),
+		Brand:           req.GetBrand(),
+		Cursor:          req.GetCursor(),
+		Limit:           int(req.GetLimit()),
+	}
+
+	switch sort := model.SortOrder(req.GetSort()); sort {
+	case "", model.SortDesc:
+		filter.Sort = model.SortDesc
+	case model.SortAsc:
+		filter.Sort = model.SortAsc
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid sort %q, expected %q or %q", sort, model.SortDesc, model.SortAsc)
+	}
+
+	if req.GetLimit() < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid limit %d", req.GetLimit())
+	}
+
+	if req.GetCreatedFrom() != nil {
+		t := req.GetCreatedFrom().AsTime()
+		filter.CreatedFrom = &t
+	}
+
+	if req.GetCreatedTo() != nil {
+		t := req.GetCreatedTo().AsTime()
+		filter.CreatedTo = &t
+	}
+
+	page, err := s.svc.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, toStatus(s.log, err)
+	}
+
+	redactOrders(ctx, page.Orders)
+
+	return &ordersv1.ListOrdersResponse{
+		Orders:     ordersToProto(page.Orders),
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// WatchOrders streams orders until the client cancels the call. The stream is finished with
+// Unavailable when the client falls behind or the service shuts down, the client should resume
+// with after_sequence set to the last received sequence. If the sequence is unknown to this instance,
+// the first message has resync set and no order.
+func (s *OrderServer) WatchOrders(req *ordersv1.WatchOrdersRequest, stream ordersv1.OrderService_WatchOrdersServer) error {
+	ctx := stream.Context()
+
+	sub := s.svc.WatchOrders(ctx, service.WatchFilter{
+		CustomerID:      req.GetCustomerId(),
+		DeliveryService: req.GetDeliveryService(),
+	}, req.GetAfterSequence())
+
+	redact := !canSeePersonalData(ctx)
+
+	send := func(n service.OrderNotification) error {
+		if redact {
+			n.Order.RedactPersonalData()
+		}
+
+		return stream.Send(&ordersv1.WatchOrdersResponse{
+			Sequence: n.Sequence,
+			Order:    orderToProto(n.Order),
+		})
+	}
+
+	if sub.Resync {
+		if err := stream.Send(&ordersv1.WatchOrdersResponse{Resync: true}); err != nil {
+			return err
+		}
+	}
+
+	for _, n := range sub.Backlog {
+		if err := send(n); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case n, ok := <-sub.C:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				return status.Error(codes.Unavailable, "watch interrupted, resume from the last sequence")
+			}
+
+			if err := send(n); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus maps err to a gRPC status by its kind in the error catalog. Details of internal
+// errors are logged and not returned to the client.
+func toStatus(log *zap.Logger, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	switch apperrors.KindOf(err) {
+	case apperrors.KindNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	case apperrors.KindValidation:
+		return status.Error(codes.InvalidArgument, err.Error())
+	case apperrors.KindConflict:
+		return status.Error(codes.AlreadyExists, err.Error())
+	case apperrors.KindUnauthorized:
+		return status.Error(codes.Unauthenticated, err.Error())
+	case apperrors.KindForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case apperrors.KindRateLimited:
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case apperrors.KindTransient, apperrors.KindUnavailable:
+		log.Warn("gRPC call failed", zap.Error(err))
+
+		return status.Error(codes.Unavailable, "service is temporarily unavailable")
+	default:
+		log.Error("gRPC call failed", zap.Error(err))
+
+		return status.Error(codes.Internal, "internal error")
+	}
+}
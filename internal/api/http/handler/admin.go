@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/pkg/kafka"
+)
+
+type ConsumerController interface {
+	Pause(partitions map[string][]int32) error
+	Resume(partitions map[string][]int32) error
+	PauseState() kafka.PauseState
+}
+
+type consumerPartitionsRequest struct {
+	// Partitions by topic, empty means all partitions.
+	Partitions map[string][]int32 `json:"partitions"`
+}
+
+func ConsumerState(consumer ConsumerController) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resp := responseWithData{
+			Status: statusSuccess,
+			Data:   consumer.PauseState(),
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func PauseConsumer(consumer ConsumerController) func(w http.ResponseWriter, r *http.Request) {
+	return changeConsumerState(consumer, consumer.Pause)
+}
+
+func ResumeConsumer(consumer ConsumerController) func(w http.ResponseWriter, r *http.Request) {
+	return changeConsumerState(consumer, consumer.Resume)
+}
+
+func changeConsumerState(consumer ConsumerController, change func(map[string][]int32) error) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req consumerPartitionsRequest
+
+		// The body is optional: no body pauses or resumes all partitions.
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("invalid request body: %s", err))
+
+			return
+		}
+
+		if err := change(req.Partitions); err != nil {
+			if errors.Is(err, kafka.ErrUnknownTopic) {
+				err = apperrors.ErrInvalidRequest.Errorf("%s", err)
+			}
+
+			problem.Write(w, r, err)
+
+			return
+		}
+
+		resp := responseWithData{
+			Status: statusSuccess,
+			Data:   consumer.PauseState(),
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+type ConsumerStatsProvider interface {
+	Stats() kafka.Stats
+}
+
+func ConsumerStats(consumer ConsumerStatsProvider) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		resp := responseWithData{
+			Status: statusSuccess,
+			Data:   consumer.Stats(),
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// orderETag returns a strong ETag of the order representation. It is derived from updated_at, which
+// the storage changes on every write, rather than from the response body, so the cache and the DB
+// give the same validator. Redacted representations have their own ETag.
+func orderETag(order model.Order, redacted bool) string {
+	sum := sha256.Sum256([]byte(order.OrderUID + "\x00" + order.UpdatedAt.UTC().Format(time.RFC3339Nano) + "\x00" + strconv.FormatBool(redacted)))
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// orderLastModified returns the time of the last change of the order.
+func orderLastModified(order model.Order) time.Time {
+	return order.UpdatedAt
+}
+
+// notModified reports whether the client copy is fresh according to RFC 9110: If-None-Match
+// is checked with the weak comparison, If-Modified-Since is only used without If-None-Match.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag)
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	if ims == "" || lastModified.IsZero() {
+		return false
+	}
+
+	t, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+
+	// HTTP dates have a one second precision.
+	return !lastModified.Truncate(time.Second).After(t)
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/model"
+)
+
+type PersonalDataEraser interface {
+	Erase(ctx context.Context, req model.ErasureRequest) (model.ErasureResult, error)
+}
+
+type erasureRequest struct {
+	CustomerID string `json:"customer_id"`
+	OrderUID   string `json:"order_uid"`
+	OnBehalfOf string `json:"on_behalf_of"`
+	// RequestedBy is the former name of OnBehalfOf.
+	RequestedBy string `json:"requested_by"`
+	Reason      string `json:"reason"`
+}
+
+// ErasePersonalData handles POST /api/admin/erasure. Exactly one of customer_id and order_uid is
+// required. The request is idempotent, a repeated one lists the orders as skipped. The audit always
+// names the authenticated client as the requester, the body can only add a note in on_behalf_of.
+func ErasePersonalData(svc PersonalDataEraser) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req erasureRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("invalid request body: %s", err))
+
+			return
+		}
+
+		erasure := model.ErasureRequest{
+			Subject:     model.ErasureSubjectCustomer,
+			SubjectID:   req.CustomerID,
+			RequestedBy: auth.FromContext(r.Context()).Subject,
+			OnBehalfOf:  req.OnBehalfOf,
+			Reason:      req.Reason,
+		}
+
+		if erasure.OnBehalfOf == "" {
+			erasure.OnBehalfOf = req.RequestedBy
+		}
+
+		switch {
+		case req.CustomerID != "" && req.OrderUID != "":
+			problem.Write(w, r, apperrors.ErrInvalidErasure.Errorf("only one of customer_id and order_uid is allowed"))
+
+			return
+		case req.OrderUID != "":
+			erasure.Subject = model.ErasureSubjectOrder
+			erasure.SubjectID = req.OrderUID
+		}
+
+		result, err := svc.Erase(r.Context(), erasure)
+		if err != nil {
+			problem.Write(w, r, err)
+
+			return
+		}
+
+		resp := responseWithData{
+			Status: statusSuccess,
+			Data:   result,
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/model"
+)
+
+type stubEraser struct {
+	req model.ErasureRequest
+}
+
+func (s *stubEraser) Erase(_ context.Context, req model.ErasureRequest) (model.ErasureResult, error) {
+	s.req = req
+
+	return model.ErasureResult{Subject: req.Subject, SubjectID: req.SubjectID}, nil
+}
+
+func TestErasePersonalDataAudit(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantSubject    model.ErasureSubject
+		wantOnBehalfOf string
+	}{
+		{
+			name:        "no note",
+			body:        `{"customer_id": "test"}`,
+			wantSubject: model.ErasureSubjectCustomer,
+		},
+		{
+			name:           "on behalf of",
+			body:           `{"order_uid": "uid", "on_behalf_of": "dpo@example.com"}`,
+			wantSubject:    model.ErasureSubjectOrder,
+			wantOnBehalfOf: "dpo@example.com",
+		},
+		{
+			name:           "requested by does not replace the client",
+			body:           `{"customer_id": "test", "requested_by": "someone-else"}`,
+			wantSubject:    model.ErasureSubjectCustomer,
+			wantOnBehalfOf: "someone-else",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubEraser{}
+
+			r := httptest.NewRequest(http.MethodPost, "/api/admin/erasure", strings.NewReader(tt.body))
+			r = r.WithContext(auth.WithPrincipal(r.Context(), auth.Principal{
+				Subject: "ops-key",
+				Method:  auth.MethodAPIKey,
+				Roles:   []auth.Role{auth.RoleAdmin},
+			}))
+
+			w := httptest.NewRecorder()
+			ErasePersonalData(svc)(w, r)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			if svc.req.RequestedBy != "ops-key" || svc.req.OnBehalfOf != tt.wantOnBehalfOf || svc.req.Subject != tt.wantSubject {
+				t.Errorf("erasure request = %+v, want requested by ops-key on behalf of %q", svc.req, tt.wantOnBehalfOf)
+			}
+		})
+	}
+}
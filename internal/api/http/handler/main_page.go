@@ -1,50 +1,37 @@
 package handler
 
 import (
-	"encoding/json"
+	"fmt"
 	"html/template"
 	"net/http"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
 )
 
 const (
 	PathToHTMLTemplate = "templates/index.html"
 )
 
-func MainPage(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-
-	t, err := template.ParseFiles(PathToHTMLTemplate)
-	if err != nil {
-		w.Header().Set("Content-Type", "application/json")
-
-		w.WriteHeader(http.StatusInternalServerError)
+type mainPageData struct {
+	OrderURL string
+}
 
-		errResp := responseWithMessage{
-			Status:  statusError,
-			Message: err.Error(),
-		}
+// MainPage serves the order search page, orderURL is the path of GET order without the order_uid.
+func MainPage(orderURL string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := template.ParseFiles(PathToHTMLTemplate)
+		if err != nil {
+			problem.Write(w, r, fmt.Errorf("failed to parse template: %w", err))
 
-		if err := json.NewEncoder(w).Encode(errResp); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
+			return
 		}
 
-		return
-	}
-
-	if err := t.Execute(w, nil); err != nil {
-		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
 
-		w.WriteHeader(http.StatusInternalServerError)
+		if err := t.Execute(w, mainPageData{OrderURL: orderURL}); err != nil {
+			problem.Write(w, r, fmt.Errorf("failed to execute template: %w", err))
 
-		errResp := responseWithMessage{
-			Status:  statusError,
-			Message: err.Error(),
+			return
 		}
-
-		if err := json.NewEncoder(w).Encode(errResp); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-		}
-
-		return
 	}
 }
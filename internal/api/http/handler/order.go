@@ -3,52 +3,57 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
 
-	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/api/http/problem"
 	"wb-tech-test-assignment/internal/model"
 )
 
 type OrderService interface {
 	GetOrder(ctx context.Context, orderUID string) (model.Order, error)
+	LookupOrders(ctx context.Context, orderUIDs []string) (model.OrderLookup, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter) (model.OrderPage, error)
 }
 
-func GetOrder(ctx context.Context, svc OrderService) func(w http.ResponseWriter, r *http.Request) {
+// GetOrder handles GET /api/order/{orderUID}. Responses carry ETag, Last-Modified and the given
+// Cache-Control, a request with a matching If-None-Match or If-Modified-Since gets 304 without a body.
+// Personal data is redacted for clients without the permission to see it.
+func GetOrder(svc OrderService, cacheControl string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
 		orderUID := chi.URLParam(r, "orderUID")
 
-		order, err := svc.GetOrder(ctx, orderUID)
+		order, err := svc.GetOrder(r.Context(), orderUID)
 		if err != nil {
-			if errors.Is(err, apperrors.ErrOrderNotFound) {
-				w.WriteHeader(http.StatusNotFound)
+			problem.Write(w, r, err)
 
-				errResp := responseWithMessage{
-					Status:  statusError,
-					Message: apperrors.ErrOrderNotFound.Error(),
-				}
+			return
+		}
+
+		redacted := !canSeePersonalData(r)
+		if redacted {
+			order.RedactPersonalData()
+		}
 
-				if err := json.NewEncoder(w).Encode(errResp); err != nil {
-					w.WriteHeader(http.StatusInternalServerError)
-				}
+		etag := orderETag(order, redacted)
+		lastModified := orderLastModified(order)
 
-				return
-			}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
 
-			w.WriteHeader(http.StatusInternalServerError)
+		// The representation depends on the roles of the client.
+		w.Header().Set("Vary", "Authorization, X-API-Key")
 
-			errResp := responseWithMessage{
-				Status:  statusError,
-				Message: err.Error(),
-			}
+		if cacheControl != "" {
+			w.Header().Set("Cache-Control", cacheControl)
+		}
 
-			if err := json.NewEncoder(w).Encode(errResp); err != nil {
-				w.WriteHeader(http.StatusInternalServerError)
-			}
+		if notModified(r, etag, lastModified) {
+			w.Header().Del("Content-Type")
+			w.WriteHeader(http.StatusNotModified)
 
 			return
 		}
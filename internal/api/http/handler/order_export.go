@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/export"
+	"wb-tech-test-assignment/internal/model"
+)
+
+type OrderExporter interface {
+	ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(model.Order) error) error
+}
+
+// ExportOrders handles GET /api/orders/export. The format query parameter is csv (default), ndjson
+// or xlsx, the other parameters are the filters of GET /api/orders. Orders are written as they are
+// read from the database, an error in the middle of the response aborts the connection.
+func ExportOrders(svc OrderExporter) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		format := export.Format(r.URL.Query().Get("format"))
+		if format == "" {
+			format = export.FormatCSV
+		}
+
+		filter, err := parseOrderFilter(r.URL.Query())
+		if err != nil {
+			problem.Write(w, r, err)
+
+			return
+		}
+
+		// The export lives longer than the server write timeout.
+		if err = http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+			problem.Write(w, r, fmt.Errorf("streaming is not supported: %w", err))
+
+			return
+		}
+
+		cw := &countingWriter{w: w}
+
+		ew, err := export.NewWriter(format, cw)
+		if err != nil {
+			problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("%s", err))
+
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="orders-%s.%s"`,
+			time.Now().UTC().Format("20060102-150405"), format))
+
+		if err = svc.ExportOrders(r.Context(), filter, ew.WriteOrder); err != nil {
+			ew.Abort()
+		} else if err = ew.Close(); err == nil {
+			return
+		}
+
+		if cw.n > 0 {
+			// The status is already sent, the client sees a broken response instead of a truncated file.
+			panic(http.ErrAbortHandler)
+		}
+
+		w.Header().Del("Content-Disposition")
+		problem.Write(w, r, err)
+	}
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+
+	return n, err
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/codec"
+	"wb-tech-test-assignment/internal/service"
+)
+
+const maxIngestBodySize = 16 << 20
+
+type OrderIngester interface {
+	Ingest(ctx context.Context, contentType string, data []byte) service.IngestResult
+	IngestBatch(ctx context.Context, messages []json.RawMessage) []service.IngestResult
+}
+
+// IngestOrders handles POST /api/orders. The body is a single message in any format supported by
+// the orders topic, or a JSON array of messages. Requests without Content-Type are treated as JSON.
+func IngestOrders(svc OrderIngester, maxBatchSize int) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIngestBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				problem.Write(w, r, apperrors.ErrRequestTooLarge.Errorf("%s", err))
+
+				return
+			}
+
+			problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("failed to read request body: %s", err))
+
+			return
+		}
+
+		contentType := r.Header.Get("Content-Type")
+		if contentType == "" {
+			contentType = codec.ContentTypeJSON
+		}
+
+		if isJSONArray(contentType, body) {
+			ingestBatch(w, r, svc, maxBatchSize, body)
+
+			return
+		}
+
+		result := svc.Ingest(r.Context(), contentType, body)
+
+		resp := responseWithData{
+			Status: statusSuccess,
+			Data:   result,
+		}
+
+		switch result.Status {
+		case service.IngestStatusAccepted:
+			w.WriteHeader(http.StatusAccepted)
+		case service.IngestStatusDuplicate:
+			w.WriteHeader(http.StatusOK)
+		case service.IngestStatusRejected:
+			resp.Status = statusError
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case service.IngestStatusFailed:
+			resp.Status = statusError
+
+			// Failures of the storage or the broker are temporary, the client may retry the message.
+			switch apperrors.Kind(result.ErrorClass) {
+			case apperrors.KindTransient, apperrors.KindUnavailable:
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusServiceUnavailable)
+			default:
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		default:
+			resp.Status = statusError
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func ingestBatch(w http.ResponseWriter, r *http.Request, svc OrderIngester, maxBatchSize int, body []byte) {
+	var messages []json.RawMessage
+	if err := json.Unmarshal(body, &messages); err != nil {
+		problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("invalid request body: %s", err))
+
+		return
+	}
+
+	if maxBatchSize > 0 && len(messages) > maxBatchSize {
+		problem.Write(w, r, apperrors.ErrRequestTooLarge.Errorf("batch of %d orders exceeds the limit of %d",
+			len(messages), maxBatchSize))
+
+		return
+	}
+
+	// Every message has its own status, so the batch itself always succeeds.
+	resp := responseWithData{
+		Status: statusSuccess,
+		Data:   svc.IngestBatch(r.Context(), messages),
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
+
+func isJSONArray(contentType string, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType != codec.ContentTypeJSON {
+		return false
+	}
+
+	body = bytes.TrimSpace(body)
+
+	return len(body) > 0 && body[0] == '['
+}
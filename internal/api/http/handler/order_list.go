@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/model"
+)
+
+// ListOrders handles GET /api/orders. Query parameters: customer_id, delivery_service, locale,
+// currency, bank, brand, created_from, created_to (RFC 3339), sort (desc or asc), limit and cursor.
+func ListOrders(svc OrderService) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		filter, err := parseOrderFilter(r.URL.Query())
+		if err != nil {
+			problem.Write(w, r, err)
+
+			return
+		}
+
+		page, err := svc.ListOrders(r.Context(), filter)
+		if err != nil {
+			problem.Write(w, r, err)
+
+			return
+		}
+
+		redactOrders(r, page.Orders)
+
+		resp := responseWithData{
+			Status: statusSuccess,
+			Data:   page,
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
+
+func parseOrderFilter(q url.Values) (model.OrderFilter, error) {
+	filter := model.OrderFilter{
+		CustomerID:      q.Get("customer_id"),
+		DeliveryService: q.Get("delivery_service"),
+		Locale:          q.Get("locale"),
+		Currency:        q.Get("currency"),
+		Bank:            q.Get("bank"),
+		Brand:           q.Get("brand"),
+		Cursor:          q.Get("cursor"),
+	}
+
+	var err error
+
+	if filter.CreatedFrom, err = parseTimeParam(q, "created_from"); err != nil {
+		return model.OrderFilter{}, err
+	}
+
+	if filter.CreatedTo, err = parseTimeParam(q, "created_to"); err != nil {
+		return model.OrderFilter{}, err
+	}
+
+	switch sort := model.SortOrder(q.Get("sort")); sort {
+	case "", model.SortDesc:
+		filter.Sort = model.SortDesc
+	case model.SortAsc:
+		filter.Sort = model.SortAsc
+	default:
+		return model.OrderFilter{}, apperrors.ErrInvalidRequest.Errorf("invalid sort %q, expected %q or %q", sort, model.SortDesc, model.SortAsc)
+	}
+
+	if v := q.Get("limit"); v != "" {
+		if filter.Limit, err = strconv.Atoi(v); err != nil || filter.Limit <= 0 {
+			return model.OrderFilter{}, apperrors.ErrInvalidRequest.Errorf("invalid limit %q", v)
+		}
+	}
+
+	return filter, nil
+}
+
+func parseTimeParam(q url.Values, name string) (*time.Time, error) {
+	v := q.Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, apperrors.ErrInvalidRequest.Errorf("invalid %s %q, expected RFC 3339 time", name, v)
+	}
+
+	return &t, nil
+}
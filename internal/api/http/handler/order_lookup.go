@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+)
+
+const defaultMaxLookupOrderUIDs = 1000
+
+type lookupOrdersRequest struct {
+	OrderUIDs []string `json:"order_uids"`
+}
+
+// LookupOrders handles POST /api/orders/lookup with the body {"order_uids": [...]}.
+func LookupOrders(svc OrderService, maxOrderUIDs int) func(w http.ResponseWriter, r *http.Request) {
+	if maxOrderUIDs <= 0 {
+		maxOrderUIDs = defaultMaxLookupOrderUIDs
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req lookupOrdersRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("invalid request body: %s", err))
+
+			return
+		}
+
+		if len(req.OrderUIDs) == 0 {
+			problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("order_uids must not be empty"))
+
+			return
+		}
+
+		if len(req.OrderUIDs) > maxOrderUIDs {
+			problem.Write(w, r, apperrors.ErrRequestTooLarge.Errorf("%d order_uids exceed the limit of %d",
+				len(req.OrderUIDs), maxOrderUIDs))
+
+			return
+		}
+
+		lookup, err := svc.LookupOrders(r.Context(), req.OrderUIDs)
+		if err != nil {
+			problem.Write(w, r, err)
+
+			return
+		}
+
+		redactOrders(r, lookup.Orders)
+
+		resp := responseWithData{
+			Status: statusSuccess,
+			Data:   lookup,
+		}
+
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+}
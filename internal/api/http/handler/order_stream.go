@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/service"
+)
+
+const defaultStreamHeartbeat = 15 * time.Second
+
+type OrderWatcher interface {
+	WatchOrders(ctx context.Context, filter service.WatchFilter, afterSequence uint64) service.OrderSubscription
+}
+
+// StreamOrders handles GET /api/orders/stream. Stored orders are sent as Server-Sent Events with
+// the sequence number as the event id, so a reconnecting client resumes from Last-Event-ID
+// (or the last_event_id query parameter). The ids are issued per instance: an id unknown to this
+// instance is answered with a resync event, after which the client should reload the orders.
+// Heartbeat comments keep idle connections open.
+func StreamOrders(svc OrderWatcher, heartbeat time.Duration) func(w http.ResponseWriter, r *http.Request) {
+	if heartbeat <= 0 {
+		heartbeat = defaultStreamHeartbeat
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		lastEventID := r.Header.Get("Last-Event-ID")
+		if lastEventID == "" {
+			lastEventID = r.URL.Query().Get("last_event_id")
+		}
+
+		var after uint64
+
+		if lastEventID != "" {
+			var err error
+			if after, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+				problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("invalid last event id %q", lastEventID))
+
+				return
+			}
+		}
+
+		rc := http.NewResponseController(w)
+
+		// The stream lives longer than the server write timeout.
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+			problem.Write(w, r, fmt.Errorf("streaming is not supported: %w", err))
+
+			return
+		}
+
+		ctx := r.Context()
+		redact := !canSeePersonalData(r)
+
+		sub := svc.WatchOrders(ctx, service.WatchFilter{
+			CustomerID:      r.URL.Query().Get("customer_id"),
+			DeliveryService: r.URL.Query().Get("delivery_service"),
+		}, after)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds()); err != nil {
+			return
+		}
+
+		if sub.Resync {
+			if _, err := fmt.Fprint(w, "event: resync\ndata: {}\n\n"); err != nil {
+				return
+			}
+		}
+
+		for _, n := range sub.Backlog {
+			if err := writeOrderEvent(w, n, redact); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case n, ok := <-sub.C:
+				// The subscription is closed on shutdown or when the client is too slow,
+				// the client reconnects with Last-Event-ID.
+				if !ok {
+					return
+				}
+
+				if err := writeOrderEvent(w, n, redact); err != nil {
+					return
+				}
+			}
+
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeOrderEvent(w http.ResponseWriter, n service.OrderNotification, redact bool) error {
+	if redact {
+		n.Order.RedactPersonalData()
+	}
+
+	data, err := json.Marshal(n.Order)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: order\ndata: %s\n\n", n.Sequence, data)
+
+	return err
+}
@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/model"
+)
+
+// canSeePersonalData reports whether the client of the request may see personal data of customers,
+// the others get orders with the data replaced by model.RedactedValue.
+func canSeePersonalData(r *http.Request) bool {
+	return auth.FromContext(r.Context()).Can(auth.PermReadPersonalData)
+}
+
+func redactOrders(r *http.Request, orders []model.Order) {
+	if canSeePersonalData(r) {
+		return
+	}
+
+	for i := range orders {
+		orders[i].RedactPersonalData()
+	}
+}
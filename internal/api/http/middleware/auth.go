@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/correlation"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// Authenticate puts the principal of the request into its context. The API key is taken from
+// X-API-Key or "Authorization: Bearer <key>", bearer tokens of three segments are verified as JWT.
+// Requests without credentials get the anonymous principal, invalid credentials are rejected with 401.
+func Authenticate(log *zap.Logger, authenticator *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := authenticator.Anonymous()
+
+			if key := r.Header.Get(HeaderAPIKey); key != "" {
+				p, ok := authenticator.APIKey(key)
+				if !ok {
+					correlation.Logger(r.Context(), log).Warn("Unknown API key", zap.String("path", r.URL.Path), zap.String("remote_addr", r.RemoteAddr))
+					unauthorized(w, r, apperrors.ErrInvalidCredentials)
+
+					return
+				}
+
+				principal = p
+			} else if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+				p, err := authenticator.Bearer(token)
+				if err != nil {
+					correlation.Logger(r.Context(), log).Warn("Invalid bearer token", zap.Error(err), zap.String("path", r.URL.Path), zap.String("remote_addr", r.RemoteAddr))
+					unauthorized(w, r, apperrors.ErrInvalidCredentials)
+
+					return
+				}
+
+				principal = p
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// Require rejects requests whose principal does not have the permission, anonymous ones with 401
+// and authenticated ones with 403.
+func Require(perm auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal := auth.FromContext(r.Context())
+
+			switch {
+			case principal.Can(perm):
+				next.ServeHTTP(w, r)
+			case principal.Method == auth.MethodAnonymous:
+				unauthorized(w, r, apperrors.ErrUnauthorized)
+			default:
+				problem.Write(w, r, apperrors.ErrForbidden)
+			}
+		})
+	}
+}
+
+func unauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	problem.Write(w, r, err)
+}
@@ -6,21 +6,50 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/correlation"
 )
 
+// Logger logs every request. Errors written by the handlers are logged with their code,
+// internal ones at the error level, since their details are not returned to clients.
 func Logger(log *zap.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			start := time.Now()
 
+			r, recorded := problem.Record(r)
+
 			next.ServeHTTP(w, r)
 
+			log := correlation.Logger(r.Context(), log)
+
 			latency := time.Since(start).Microseconds()
-			log.Info("request",
+			fields := []zap.Field{
 				zap.String("method", r.Method),
 				zap.String("path", r.URL.Path),
 				zap.String("latency", fmt.Sprintf("%d µs", latency)),
+			}
+
+			err := recorded()
+			if err == nil {
+				log.Info("request", fields...)
+
+				return
+			}
+
+			fields = append(fields,
+				zap.Int("status", problem.Status(err)),
+				zap.String("error_code", apperrors.CodeOf(err)),
+				zap.Error(err),
 			)
+
+			if apperrors.KindOf(err) == apperrors.KindInternal {
+				log.Error("request", fields...)
+			} else {
+				log.Info("request", fields...)
+			}
 		})
 	}
 }
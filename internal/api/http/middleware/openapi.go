@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/correlation"
+)
+
+func init() {
+	// Binary bodies are checked only for presence, their content is validated by the codecs.
+	openapi3filter.RegisterBodyDecoder("application/x-protobuf", openapi3filter.FileBodyDecoder)
+	openapi3filter.RegisterBodyDecoder("application/avro", openapi3filter.FileBodyDecoder)
+}
+
+// OpenAPIValidator rejects requests that do not match the document with 400. If validateResponses
+// is set, responses are checked too and mismatches are logged, this is meant for development only.
+// Requests to routes missing from the document are passed as is.
+func OpenAPIValidator(log *zap.Logger, doc *openapi3.T, validateResponses bool) (func(http.Handler) http.Handler, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	options := &openapi3filter.Options{
+		// Authentication is checked by the auth middleware.
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				if !errors.Is(err, routers.ErrPathNotFound) && !errors.Is(err, routers.ErrMethodNotAllowed) {
+					correlation.Logger(r.Context(), log).Warn("Failed to find openapi route", zap.Error(err), zap.String("path", r.URL.Path))
+				}
+
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    r,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    options,
+			}
+
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				// Routes documented with problems only get them here too, the router has not chosen the route yet.
+				if resp := route.Operation.Responses.Status(http.StatusBadRequest); resp != nil && resp.Value != nil &&
+					resp.Value.Content.Get("application/json") == nil && resp.Value.Content.Get(problem.ContentType) != nil {
+					r = problem.Preferred(r)
+				}
+
+				problem.Write(w, r, apperrors.ErrInvalidRequest.Errorf("request does not match the API specification: %s", err))
+
+				return
+			}
+
+			if !validateResponses {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r)
+
+			// Event streams and file exports are neither kept nor validated.
+			if rec.streaming {
+				return
+			}
+
+			responseInput := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: input,
+				Status:                 rec.status,
+				Header:                 w.Header(),
+				Options:                options,
+			}
+			responseInput.SetBodyBytes(rec.body.Bytes())
+
+			if err := openapi3filter.ValidateResponse(r.Context(), responseInput); err != nil {
+				correlation.Logger(r.Context(), log).Error("Response does not match the API specification", zap.Error(err),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.Int("status", rec.status),
+				)
+			}
+		})
+	}, nil
+}
+
+// responseRecorder passes the response to the client and keeps a copy of it for validation.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	streaming   bool
+	body        bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+		r.streaming = strings.HasPrefix(r.Header().Get("Content-Type"), "text/event-stream") ||
+			r.Header().Get("Content-Disposition") != ""
+	}
+
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if !r.wroteHeader {
+		r.WriteHeader(http.StatusOK)
+	}
+
+	if !r.streaming {
+		r.body.Write(b)
+	}
+
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/correlation"
+	"wb-tech-test-assignment/internal/ratelimit"
+)
+
+// RateLimit limits requests of every authenticated client by a token bucket, the client is identified
+// by the principal set by Authenticate. Anonymous requests are left to RateLimitByIP. Responses carry
+// RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset, rejected requests get 429 with Retry-After.
+// Requests are allowed if the limiter fails, for example if Redis is unavailable.
+func RateLimit(log *zap.Logger, limiter ratelimit.Limiter) func(http.Handler) http.Handler {
+	return limit(log, limiter, func(r *http.Request) (string, bool) {
+		p := auth.FromContext(r.Context())
+
+		return p.Method + ":" + p.Subject, p.Method != auth.MethodAnonymous
+	})
+}
+
+// RateLimitByIP limits requests of every IP address by a token bucket. It runs before Authenticate,
+// so requests with invalid credentials are limited as well and cannot be used to guess keys.
+func RateLimitByIP(log *zap.Logger, limiter ratelimit.Limiter) func(http.Handler) http.Handler {
+	return limit(log, limiter, func(r *http.Request) (string, bool) {
+		return ipKey(r), true
+	})
+}
+
+// limit takes a token from the bucket of the key of the request, requests without a key pass.
+func limit(log *zap.Logger, limiter ratelimit.Limiter, key func(r *http.Request) (string, bool)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k, ok := key(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			res, err := limiter.Allow(r.Context(), k)
+			if err != nil {
+				correlation.Logger(r.Context(), log).Warn("Failed to check rate limit", zap.Error(err))
+				next.ServeHTTP(w, r)
+
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+			if !res.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(max(ceilSeconds(res.RetryAfter), 1)))
+				problem.Write(w, r, apperrors.ErrRateLimited)
+
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// InFlight rejects requests with 429 while limit requests are being handled by the server.
+// Zero limit disables the middleware.
+func InFlight(limit int) func(http.Handler) http.Handler {
+	sem := make(chan struct{}, max(limit, 0))
+
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case sem <- struct{}{}:
+			default:
+				w.Header().Set("Retry-After", "1")
+				problem.Write(w, r, apperrors.ErrTooManyInFlight)
+
+				return
+			}
+
+			defer func() {
+				<-sem
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func ipKey(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/ratelimit"
+)
+
+func newTestAuthenticator(t *testing.T, key string) *auth.Authenticator {
+	t.Helper()
+
+	sum := sha256.Sum256([]byte(key))
+
+	apiKey, err := auth.NewAPIKey("client", hex.EncodeToString(sum[:]), []string{"reader"})
+	if err != nil {
+		t.Fatalf("NewAPIKey() error = %v", err)
+	}
+
+	return auth.NewAuthenticator(auth.WithAPIKeys(apiKey), auth.WithAnonymousRoles(auth.RoleReader))
+}
+
+// chain is the order of the middlewares of the API routes. The limiters have a bucket of burst
+// requests that is not refilled during the test.
+func chain(t *testing.T, burst int) http.Handler {
+	t.Helper()
+
+	log := zap.NewNop()
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return RateLimitByIP(log, ratelimit.NewLocal(0.001, burst))(
+		Authenticate(log, newTestAuthenticator(t, "valid-key"))(
+			RateLimit(log, ratelimit.NewLocal(0.001, burst))(ok),
+		),
+	)
+}
+
+func TestRateLimitInvalidCredentials(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		// want are the statuses of the consecutive requests from one address.
+		want []int
+	}{
+		{
+			name:   "invalid API key",
+			header: HeaderAPIKey,
+			value:  "guess",
+			want:   []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests},
+		},
+		{
+			name:   "invalid bearer token",
+			header: "Authorization",
+			value:  "Bearer a.b.c",
+			want:   []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests},
+		},
+		{
+			name: "anonymous",
+			want: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+		{
+			name:   "valid API key",
+			header: HeaderAPIKey,
+			value:  "valid-key",
+			want:   []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := chain(t, 2)
+
+			for i, want := range tt.want {
+				r := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+				r.RemoteAddr = "192.0.2.1:40000"
+
+				if tt.header != "" {
+					r.Header.Set(tt.header, tt.value)
+				}
+
+				w := httptest.NewRecorder()
+				h.ServeHTTP(w, r)
+
+				if w.Code != want {
+					t.Errorf("request %d: status = %d, want %d", i, w.Code, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitByIPAddresses(t *testing.T) {
+	h := chain(t, 1)
+
+	for _, addr := range []string{"192.0.2.1:40000", "192.0.2.2:40000"} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/orders", nil)
+		r.RemoteAddr = addr
+		r.Header.Set(HeaderAPIKey, "guess")
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("first request from %s: status = %d, want %d", addr, w.Code, http.StatusUnauthorized)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+
+	"wb-tech-test-assignment/internal/correlation"
+)
+
+// RequestID takes the correlation ID of the request from X-Request-ID or generates one if the header
+// is missing or invalid. The ID is echoed in the response and carried by the request context.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(correlation.HeaderHTTP)
+		if !correlation.Valid(id) {
+			id = correlation.NewID()
+		}
+
+		w.Header().Set(correlation.HeaderHTTP, id)
+
+		next.ServeHTTP(w, r.WithContext(correlation.WithID(r.Context(), id)))
+	})
+}
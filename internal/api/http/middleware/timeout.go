@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"wb-tech-test-assignment/internal/apperrors"
+)
+
+// Timeout cancels the context of the request after timeout, the database and cache calls made
+// with it fail and the handler answers with 504. The context is also cancelled when the client
+// goes away. Zero timeout disables the middleware.
+func Timeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeoutCause(r.Context(), timeout, apperrors.ErrRequestTimeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
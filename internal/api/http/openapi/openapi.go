@@ -0,0 +1,115 @@
+// Package openapi embeds the OpenAPI document of the HTTP API.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Options describe how the API is mounted by the server.
+type Options struct {
+	BasePath string
+
+	// Versions maps mounted versions ("v1", "v2") to whether they are deprecated,
+	// paths of other versions are removed from the document.
+	Versions map[string]bool
+
+	// Unversioned adds deprecated aliases of the v1 paths without the version prefix.
+	Unversioned bool
+}
+
+// Load parses the embedded document, adjusts it to the options and validates it.
+func Load(ctx context.Context, opts Options) (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi document: %w", err)
+	}
+
+	basePath := strings.TrimSuffix(opts.BasePath, "/")
+	if basePath == "" {
+		basePath = "/"
+	}
+
+	doc.Servers = openapi3.Servers{{URL: basePath}}
+
+	for path, item := range maps.Clone(doc.Paths.Map()) {
+		version, rest, ok := versionOf(path)
+		if !ok {
+			continue
+		}
+
+		deprecated, mounted := opts.Versions[version]
+		if !mounted {
+			doc.Paths.Delete(path)
+
+			continue
+		}
+
+		if deprecated {
+			markDeprecated(item, "")
+		}
+
+		if opts.Unversioned && version == "v1" {
+			alias := *item
+			markDeprecated(&alias, "Unversioned")
+
+			doc.Paths.Set(rest, &alias)
+		}
+	}
+
+	if err = doc.Validate(ctx); err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// Handler serves the document.
+func Handler(doc *openapi3.T) (http.HandlerFunc, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal openapi document: %w", err)
+	}
+
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		_, _ = w.Write(data)
+	}, nil
+}
+
+// versionOf splits /v1/orders into v1 and /orders.
+func versionOf(path string) (string, string, bool) {
+	version, rest, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !ok || len(version) < 2 || version[0] != 'v' || strings.Trim(version[1:], "0123456789") != "" {
+		return "", "", false
+	}
+
+	return version, "/" + rest, true
+}
+
+// markDeprecated marks the operations of the item as deprecated. Operations of aliases get their own
+// operation ids with the suffix, the item is copied operation by operation for that.
+func markDeprecated(item *openapi3.PathItem, operationIDSuffix string) {
+	for method, op := range item.Operations() {
+		if operationIDSuffix != "" {
+			alias := *op
+			alias.OperationID += operationIDSuffix
+			op = &alias
+
+			item.SetOperation(method, op)
+		}
+
+		op.Deprecated = true
+	}
+}
@@ -0,0 +1,169 @@
+// Package problem writes errors of the HTTP API. The status is chosen by the kind of the error
+// in the catalog (internal/apperrors), the body is either an RFC 7807 problem or the legacy
+// {"status": "error", "message": ...} envelope.
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"wb-tech-test-assignment/internal/apperrors"
+)
+
+const (
+	ContentType = "application/problem+json"
+
+	// TypePrefix is followed by the error code in the type of a problem.
+	TypePrefix = "urn:wb-tech:problem:"
+
+	// retryAfter is suggested to clients in the Retry-After header of 503 responses, in seconds.
+	retryAfter = 1
+)
+
+// Problem is an RFC 7807 problem details object extended with the error code.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+}
+
+type envelope struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Code    string `json:"code"`
+}
+
+type contextKey int
+
+const (
+	preferKey contextKey = iota
+	recorderKey
+)
+
+// Prefer makes Write answer with problems regardless of the Accept header of the request.
+func Prefer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, Preferred(r))
+	})
+}
+
+// Preferred returns the request for which Write answers with problems.
+func Preferred(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), preferKey, true))
+}
+
+// Record returns the request with a recorder of the error written by Write and a function that
+// reports it, nil if no error was written. It is used by the request logger.
+func Record(r *http.Request) (*http.Request, func() error) {
+	rec := new(error)
+
+	return r.WithContext(context.WithValue(r.Context(), recorderKey, rec)), func() error {
+		return *rec
+	}
+}
+
+// Status returns the HTTP status for err.
+func Status(err error) int {
+	switch {
+	case errors.Is(err, apperrors.ErrRequestTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, apperrors.ErrRequestTimeout):
+		return http.StatusGatewayTimeout
+	}
+
+	switch apperrors.KindOf(err) {
+	case apperrors.KindNotFound:
+		return http.StatusNotFound
+	case apperrors.KindValidation:
+		return http.StatusBadRequest
+	case apperrors.KindConflict:
+		return http.StatusConflict
+	case apperrors.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperrors.KindForbidden:
+		return http.StatusForbidden
+	case apperrors.KindRateLimited:
+		return http.StatusTooManyRequests
+	case apperrors.KindTransient, apperrors.KindUnavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Write writes err with the status of its kind. Messages of not found, validation and conflict
+// errors are returned as is, the others are replaced with a generic message, so that details of
+// the storage do not reach clients.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	// Calls interrupted by the deadline of the request only report context.DeadlineExceeded.
+	if errors.Is(err, context.DeadlineExceeded) {
+		if cause := context.Cause(r.Context()); errors.Is(cause, apperrors.ErrRequestTimeout) {
+			err = fmt.Errorf("%w: %w", cause, err)
+		}
+	}
+
+	if rec, ok := r.Context().Value(recorderKey).(*error); ok {
+		*rec = err
+	}
+
+	status := Status(err)
+	code := apperrors.CodeOf(err)
+
+	var detail string
+
+	switch apperrors.KindOf(err) {
+	case apperrors.KindNotFound, apperrors.KindValidation, apperrors.KindConflict,
+		apperrors.KindUnauthorized, apperrors.KindForbidden, apperrors.KindRateLimited:
+		detail = err.Error()
+	case apperrors.KindTransient, apperrors.KindUnavailable:
+		detail = "service is temporarily unavailable, retry later"
+		if status == http.StatusGatewayTimeout {
+			detail = apperrors.ErrRequestTimeout.Error()
+		}
+
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	default:
+		detail = "internal error"
+	}
+
+	if !wantsProblem(r) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+
+		_ = json.NewEncoder(w).Encode(envelope{
+			Status:  "error",
+			Message: detail,
+			Code:    code,
+		})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(Problem{
+		Type:     TypePrefix + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+	})
+}
+
+func wantsProblem(r *http.Request) bool {
+	if prefer, _ := r.Context().Value(preferKey).(bool); prefer {
+		return true
+	}
+
+	return strings.Contains(r.Header.Get("Accept"), ContentType)
+}
@@ -0,0 +1,229 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"wb-tech-test-assignment/internal/apperrors"
+)
+
+func TestStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: apperrors.ErrOrderNotFound, want: http.StatusNotFound},
+		{name: "wrapped not found", err: fmt.Errorf("failed to get order: %w", apperrors.ErrOrderNotFound), want: http.StatusNotFound},
+		{name: "validation", err: apperrors.ErrInvalidCursor, want: http.StatusBadRequest},
+		{name: "validation with a message", err: apperrors.ErrInvalidRequest.Errorf("limit must be positive"), want: http.StatusBadRequest},
+		{name: "too large", err: apperrors.ErrRequestTooLarge, want: http.StatusRequestEntityTooLarge},
+		{name: "conflict", err: apperrors.ErrOrderConflict, want: http.StatusConflict},
+		{name: "unauthorized", err: apperrors.ErrInvalidCredentials, want: http.StatusUnauthorized},
+		{name: "forbidden", err: apperrors.ErrForbidden, want: http.StatusForbidden},
+		{name: "rate limited", err: apperrors.ErrTooManyInFlight, want: http.StatusTooManyRequests},
+		{name: "timeout", err: apperrors.ErrRequestTimeout, want: http.StatusGatewayTimeout},
+		{name: "publish", err: fmt.Errorf("%w: %w", apperrors.ErrOrderPublish, errors.New("broker down")), want: http.StatusServiceUnavailable},
+		{name: "shutdown", err: apperrors.ErrShutdown, want: http.StatusServiceUnavailable},
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: http.StatusServiceUnavailable},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: http.StatusServiceUnavailable},
+		{name: "canceled", err: context.Canceled, want: http.StatusServiceUnavailable},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: http.StatusInternalServerError},
+		{name: "unknown", err: errors.New("boom"), want: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Status(tt.err); got != tt.want {
+				t.Errorf("Status() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		problem bool
+		accept  string
+
+		wantStatus      int
+		wantContentType string
+		wantCode        string
+		wantDetail      string
+		wantRetryAfter  string
+	}{
+		{
+			name:            "legacy envelope",
+			err:             apperrors.ErrOrderNotFound,
+			wantStatus:      http.StatusNotFound,
+			wantContentType: "application/json",
+			wantCode:        "order_not_found",
+			wantDetail:      "order not found",
+		},
+		{
+			name:            "problem by Accept",
+			err:             apperrors.ErrInvalidRequest.Errorf("invalid limit %q", "x"),
+			accept:          "application/problem+json, application/json",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: ContentType,
+			wantCode:        "invalid_request",
+			wantDetail:      `invalid limit "x"`,
+		},
+		{
+			name:            "problem preferred",
+			err:             apperrors.ErrForbidden,
+			problem:         true,
+			wantStatus:      http.StatusForbidden,
+			wantContentType: ContentType,
+			wantCode:        "forbidden",
+			wantDetail:      apperrors.ErrForbidden.Error(),
+		},
+		{
+			name:            "internal details are hidden",
+			err:             errors.New(`relation "orders" does not exist`),
+			problem:         true,
+			wantStatus:      http.StatusInternalServerError,
+			wantContentType: ContentType,
+			wantCode:        "internal",
+			wantDetail:      "internal error",
+		},
+		{
+			name:            "broker details are hidden",
+			err:             fmt.Errorf("%w: %w", apperrors.ErrOrderPublish, errors.New("kafka: client has run out of available brokers")),
+			wantStatus:      http.StatusServiceUnavailable,
+			wantContentType: "application/json",
+			wantCode:        "order_publish_failed",
+			wantDetail:      "service is temporarily unavailable, retry later",
+			wantRetryAfter:  "1",
+		},
+		{
+			name:            "transient storage failure",
+			err:             &pgconn.PgError{Code: "40P01", Message: "deadlock detected"},
+			problem:         true,
+			wantStatus:      http.StatusServiceUnavailable,
+			wantContentType: ContentType,
+			wantCode:        "transient",
+			wantDetail:      "service is temporarily unavailable, retry later",
+			wantRetryAfter:  "1",
+		},
+		{
+			name:            "timeout",
+			err:             apperrors.ErrRequestTimeout,
+			problem:         true,
+			wantStatus:      http.StatusGatewayTimeout,
+			wantContentType: ContentType,
+			wantCode:        "request_timeout",
+			wantDetail:      "request timed out",
+			wantRetryAfter:  "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/orders/uid", nil)
+			if tt.accept != "" {
+				r.Header.Set("Accept", tt.accept)
+			}
+
+			if tt.problem {
+				r = Preferred(r)
+			}
+
+			w := httptest.NewRecorder()
+			Write(w, r, tt.err)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if got := w.Header().Get("Content-Type"); got != tt.wantContentType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantContentType)
+			}
+
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetryAfter {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetryAfter)
+			}
+
+			if tt.wantContentType == ContentType {
+				var p Problem
+				if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+					t.Fatalf("failed to unmarshal problem: %v", err)
+				}
+
+				want := Problem{
+					Type:     TypePrefix + tt.wantCode,
+					Title:    http.StatusText(tt.wantStatus),
+					Status:   tt.wantStatus,
+					Detail:   tt.wantDetail,
+					Instance: "/api/v1/orders/uid",
+					Code:     tt.wantCode,
+				}
+
+				if p != want {
+					t.Errorf("problem = %+v, want %+v", p, want)
+				}
+
+				return
+			}
+
+			var e envelope
+			if err := json.Unmarshal(w.Body.Bytes(), &e); err != nil {
+				t.Fatalf("failed to unmarshal envelope: %v", err)
+			}
+
+			if want := (envelope{Status: "error", Message: tt.wantDetail, Code: tt.wantCode}); e != want {
+				t.Errorf("envelope = %+v, want %+v", e, want)
+			}
+		})
+	}
+}
+
+func TestWriteRequestDeadline(t *testing.T) {
+	tests := []struct {
+		name       string
+		cause      error
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "request timeout", cause: apperrors.ErrRequestTimeout, wantStatus: http.StatusGatewayTimeout, wantCode: "request_timeout"},
+		{name: "other deadline", cause: context.DeadlineExceeded, wantStatus: http.StatusServiceUnavailable, wantCode: "transient"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			cancel(tt.cause)
+
+			r, errOf := Record(httptest.NewRequestWithContext(ctx, http.MethodGet, "/api/v1/orders", nil))
+			w := httptest.NewRecorder()
+
+			Write(w, r, fmt.Errorf("failed to list orders: %w", context.DeadlineExceeded))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if got := apperrors.CodeOf(errOf()); got != tt.wantCode {
+				t.Errorf("code of the recorded error = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRecordWithoutError(t *testing.T) {
+	_, errOf := Record(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := errOf(); err != nil {
+		t.Errorf("recorded error = %v, want nil", err)
+	}
+}
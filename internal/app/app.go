@@ -4,16 +4,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
+	grpchandler "wb-tech-test-assignment/internal/api/grpc/handler"
 	"wb-tech-test-assignment/internal/api/http/handler"
 	"wb-tech-test-assignment/internal/api/http/middleware"
+	"wb-tech-test-assignment/internal/api/http/openapi"
 	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/codec"
 	"wb-tech-test-assignment/internal/config"
+	"wb-tech-test-assignment/internal/metrics"
 	"wb-tech-test-assignment/internal/repository"
+	"wb-tech-test-assignment/internal/rules"
 	"wb-tech-test-assignment/internal/service"
+	ordersv1 "wb-tech-test-assignment/pkg/api/orders/v1"
 	"wb-tech-test-assignment/pkg/kafka"
 	"wb-tech-test-assignment/pkg/postgres"
 	"wb-tech-test-assignment/pkg/redis"
@@ -26,8 +40,14 @@ type App struct {
 	DB         postgres.Postgres
 	RDB        redis.Redis
 	Consumer   kafka.ConsumerGroupRunner
+	DLQ        kafka.Producer
+	Publisher  kafka.Producer
 	HTTPServer server.HTTPServer
-	Service    *Service
+	GRPCServer server.GRPCServer
+	// MetricsServer is nil if metrics are disabled.
+	MetricsServer server.HTTPServer
+	Health        *health.Server
+	Service       *Service
 }
 
 type Repository struct {
@@ -35,7 +55,9 @@ type Repository struct {
 }
 
 type Service struct {
-	OrderService *service.OrderService
+	OrderService   *service.OrderService
+	IngestService  *service.IngestService
+	ErasureService *service.ErasureService
 }
 
 func New(ctx context.Context, cfg *config.Config, log *zap.Logger) (*App, error) {
@@ -60,20 +82,81 @@ func New(ctx context.Context, cfg *config.Config, log *zap.Logger) (*App, error)
 		return nil, fmt.Errorf("failed to initialize kafka: %w", err)
 	}
 
-	repo := initRepository(ctx, log, db, rdb, cfg.Enable)
+	dlq, err := initDeadLetterProducer(&cfg.Kafka)
+	if err != nil {
+		log.Error("Failed to initialize dead letter producer", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to initialize dead letter producer: %w", err)
+	}
+
+	publisher, err := initOrdersProducer(&cfg.Kafka, &cfg.HTTPServer.Ingestion)
+	if err != nil {
+		log.Error("Failed to initialize orders producer", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to initialize orders producer: %w", err)
+	}
+
+	codecs, err := initCodecs(&cfg.Subscriber)
+	if err != nil {
+		log.Error("Failed to initialize codecs", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to initialize codecs: %w", err)
+	}
+
+	rulesEngine, err := rules.NewEngine(cfg.Validation.Rules)
+	if err != nil {
+		log.Error("Failed to initialize validation rules", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to initialize validation rules: %w", err)
+	}
+
+	log.Info("Validation rules enabled", zap.Strings("rules", rulesEngine.Rules()))
+
+	repo := initRepository(ctx, log, db, rdb, cfg)
+
+	svc, err := initService(log, cfg, consumer, dlq, publisher, codecs, rulesEngine, db, repo)
+	if err != nil {
+		log.Error("Failed to initialize service", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to initialize service: %w", err)
+	}
+
+	metricsRegistry, err := metrics.NewRegistry(metrics.NewKafkaConsumerCollector(consumer))
+	if err != nil {
+		log.Error("Failed to initialize metrics", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to initialize metrics: %w", err)
+	}
+
+	authenticator, err := initAuthenticator(log, cfg.HTTPServer)
+	if err != nil {
+		log.Error("Failed to initialize authentication", zap.Error(err))
 
-	svc := initService(log, &cfg.Subscriber, consumer, db, repo)
+		return nil, fmt.Errorf("failed to initialize authentication: %w", err)
+	}
+
+	httpServer, err := initHTTPServer(ctx, log, cfg.HTTPServer, svc, consumer, authenticator, rdb)
+	if err != nil {
+		log.Error("Failed to initialize http server", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to initialize http server: %w", err)
+	}
 
-	httpServer := initHTTPServer(ctx, log, cfg.HTTPServer, svc.OrderService)
+	grpcServer, healthServer := initGRPCServer(log, cfg, svc, authenticator)
 
 	return &App{
-		Cfg:        cfg,
-		Log:        log,
-		DB:         db,
-		RDB:        rdb,
-		Consumer:   consumer,
-		HTTPServer: httpServer,
-		Service:    svc,
+		Cfg:           cfg,
+		Log:           log,
+		DB:            db,
+		RDB:           rdb,
+		Consumer:      consumer,
+		DLQ:           dlq,
+		Publisher:     publisher,
+		HTTPServer:    httpServer,
+		GRPCServer:    grpcServer,
+		MetricsServer: initMetricsServer(cfg.MetricsServer, metricsRegistry),
+		Health:        healthServer,
+		Service:       svc,
 	}, nil
 }
 
@@ -86,9 +169,10 @@ func MustNew(ctx context.Context, cfg *config.Config, log *zap.Logger) *App {
 	return app
 }
 
+// Run starts the consumer and the servers and returns the first error of any of them. The channel
+// has room for all of them, so the others do not block after Run has returned.
 func (a *App) Run(ctx context.Context) error {
-	errs := make(chan error)
-	defer close(errs)
+	errs := make(chan error, 4)
 
 	go func() {
 		if err := a.Service.OrderService.Run(ctx); err != nil {
@@ -102,6 +186,22 @@ func (a *App) Run(ctx context.Context) error {
 		}
 	}()
 
+	if a.GRPCServer != nil {
+		go func() {
+			if err := a.GRPCServer.Run(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	if a.MetricsServer != nil {
+		go func() {
+			if err := a.MetricsServer.Run(); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
 	if err := <-errs; err != nil {
 		return err
 	}
@@ -114,7 +214,7 @@ func (a *App) Shutdown() error {
 
 	a.Log.Debug("Database closed")
 
-	err := apperrors.ErrShutdown
+	var err error = apperrors.ErrShutdown
 
 	if rdbErr := a.RDB.Close(); rdbErr != nil {
 		err = fmt.Errorf("%w, failed to close RDB: %w", err, rdbErr)
@@ -128,12 +228,46 @@ func (a *App) Shutdown() error {
 
 	a.Log.Debug("Order service shutdown")
 
+	if a.DLQ != nil {
+		if dlqErr := a.DLQ.Close(); dlqErr != nil {
+			err = fmt.Errorf("%w, failed to close dead letter producer: %w", err, dlqErr)
+		}
+
+		a.Log.Debug("Dead letter producer closed")
+	}
+
 	if srvErr := a.HTTPServer.Shutdown(); srvErr != nil {
 		err = fmt.Errorf("%w, failed to shutdown http server: %w", err, srvErr)
 	}
 
 	a.Log.Debug("Http server shutdown")
 
+	if a.GRPCServer != nil {
+		a.Health.Shutdown()
+
+		if grpcErr := a.GRPCServer.Shutdown(); grpcErr != nil {
+			err = fmt.Errorf("%w, failed to shutdown grpc server: %w", err, grpcErr)
+		}
+
+		a.Log.Debug("gRPC server shutdown")
+	}
+
+	if a.MetricsServer != nil {
+		if metricsErr := a.MetricsServer.Shutdown(); metricsErr != nil {
+			err = fmt.Errorf("%w, failed to shutdown metrics server: %w", err, metricsErr)
+		}
+
+		a.Log.Debug("Metrics server shutdown")
+	}
+
+	if a.Publisher != nil {
+		if pubErr := a.Publisher.Close(); pubErr != nil {
+			err = fmt.Errorf("%w, failed to close orders producer: %w", err, pubErr)
+		}
+
+		a.Log.Debug("Orders producer closed")
+	}
+
 	if !errors.Is(err, apperrors.ErrShutdown) {
 		return err
 	}
@@ -202,13 +336,64 @@ func initKafka(cfg *config.Kafka, log *zap.Logger) (kafka.ConsumerGroupRunner, e
 	return consumerGroup, nil
 }
 
-func initRepository(ctx context.Context, log *zap.Logger, db postgres.Postgres, rdb redis.Redis, enableCache bool) *Repository {
-	orderRepository := repository.NewOrderRepository(db.Pool())
+// initDeadLetterProducer returns nil if the dead letter topic is disabled.
+func initDeadLetterProducer(cfg *config.Kafka) (kafka.Producer, error) {
+	if !cfg.Subscriber.DeadLetter.Enable {
+		return nil, nil
+	}
+
+	producer, err := kafka.NewProducer(
+		cfg.Brokers,
+		cfg.Subscriber.DeadLetter.Topic,
+		kafka.WithBalancer(kafka.Hash),
+		kafka.WithRequiredAcks(kafka.RequireAll),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return producer, nil
+}
+
+// initOrdersProducer creates the producer used by the HTTP ingestion API, it is only needed in the publish mode.
+func initOrdersProducer(cfg *config.Kafka, ingestion *config.Ingestion) (kafka.Producer, error) {
+	if service.IngestMode(ingestion.Mode) != service.IngestModePublish {
+		return nil, nil
+	}
+
+	producer, err := kafka.NewProducer(
+		cfg.Brokers,
+		cfg.Producer.OrdersProducer.Topic,
+		kafka.WithBalancer(kafka.Hash),
+		kafka.WithRequiredAcks(kafka.RequireAll),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return producer, nil
+}
+
+func initCodecs(cfg *config.Subscriber) (*codec.Registry, error) {
+	defaultCodec := cfg.OrdersSubscriber.Codec
+	if defaultCodec == "" {
+		defaultCodec = codec.NameJSON
+	}
+
+	return codec.NewRegistry(defaultCodec,
+		codec.NewJSON(),
+		codec.NewProtobuf(),
+		codec.NewAvro(codec.NewFileSchemaRegistry(cfg.SchemaRegistry.Path)),
+	)
+}
+
+func initRepository(ctx context.Context, log *zap.Logger, db postgres.Postgres, rdb redis.Redis, cfg *config.Config) *Repository {
+	orderRepository := repository.NewOrderRepository(db.Pool(), repository.ConflictPolicy(cfg.ConflictPolicy))
 
-	if enableCache {
+	if cfg.Redis.Enable {
 		log.Info("Cache enabled")
 
-		orderWithCacheRepository := repository.NewOrderWithCacheRepository(rdb.RDB(), orderRepository)
+		orderWithCacheRepository := repository.NewOrderWithCacheRepository(log, rdb.RDB(), orderRepository)
 
 		go func() {
 			if err := orderWithCacheRepository.WarmupCache(ctx); err != nil {
@@ -228,22 +413,161 @@ func initRepository(ctx context.Context, log *zap.Logger, db postgres.Postgres,
 	}
 }
 
-func initService(log *zap.Logger, cfg *config.Subscriber, consumer kafka.ConsumerGroupRunner, db postgres.Postgres, repo *Repository) *Service {
-	orderService := service.NewOrderService(log, cfg, consumer, db, repo.OrderRepository)
+func initService(log *zap.Logger, cfg *config.Config, consumer kafka.ConsumerGroupRunner, dlq, publisher kafka.Producer, codecs *codec.Registry, rulesEngine *rules.Engine, db postgres.Postgres, repo *Repository) (*Service, error) {
+	orderService := service.NewOrderService(log, &cfg.Subscriber, consumer, dlq, codecs, rulesEngine, db, repo.OrderRepository)
+
+	mode := service.IngestMode(cfg.HTTPServer.Ingestion.Mode)
+	if mode == "" {
+		mode = service.IngestModePersist
+	}
+
+	ingestService, err := service.NewIngestService(log, orderService, mode, publisher)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Service{
-		OrderService: orderService,
+		OrderService:   orderService,
+		IngestService:  ingestService,
+		ErasureService: service.NewErasureService(log, repo.OrderRepository, orderService),
+	}, nil
+}
+
+// initGRPCServer returns nil servers if the gRPC API is disabled. Calls are authenticated
+// with the credentials of the HTTP API.
+func initGRPCServer(log *zap.Logger, cfg *config.Config, svc *Service, authenticator *auth.Authenticator) (server.GRPCServer, *health.Server) {
+	if !cfg.GRPCServer.Enable {
+		return nil, nil
+	}
+
+	srv := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(grpchandler.UnaryAuth(log, authenticator)),
+		grpc.ChainStreamInterceptor(grpchandler.StreamAuth(log, authenticator)),
+	)
+
+	ordersv1.RegisterOrderServiceServer(srv, grpchandler.NewOrderServer(log, svc.OrderService, cfg.HTTPServer.Lookup.MaxOrderUIDs))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(ordersv1.OrderService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, healthServer)
+
+	if cfg.GRPCServer.Reflection {
+		reflection.Register(srv)
 	}
+
+	return server.NewGRPCServer(srv, cfg.GRPCServer.Host, cfg.GRPCServer.Port), healthServer
 }
 
-func initHTTPServer(ctx context.Context, log *zap.Logger, cfg config.HTTPServer, svc *service.OrderService) server.HTTPServer {
+// initMetricsServer returns nil if metrics are disabled.
+func initMetricsServer(cfg config.MetricsServer, reg *prometheus.Registry) server.HTTPServer {
+	if !cfg.Enable {
+		return nil
+	}
+
+	return server.NewHTTPServer(
+		server.WithAddr(cfg.Host, cfg.Port),
+		server.WithHandler(metricsRouter(reg)),
+	)
+}
+
+func metricsRouter(reg *prometheus.Registry) http.Handler {
 	r := chi.NewRouter()
+	r.Handle("/metrics", metrics.Handler(reg))
 
-	r.Use(middleware.Logger(log))
+	return r
+}
 
-	r.Get("/", handler.MainPage)
-	r.Get("/api/ping", handler.Ping)
-	r.Get("/api/order/{orderUID}", handler.GetOrder(ctx, svc))
+func initHTTPServer(ctx context.Context, log *zap.Logger, cfg config.HTTPServer, svc *Service, consumer kafka.ConsumerGroupRunner, authenticator *auth.Authenticator, rdb redis.Redis) (server.HTTPServer, error) {
+	basePath := strings.TrimSuffix(cfg.BasePath, "/")
+	inFlight := middleware.InFlight(cfg.RateLimit.MaxInFlight)
+	versions := apiVersions(cfg, svc, inFlight)
+
+	docOpts := openapi.Options{
+		BasePath:    basePath,
+		Versions:    make(map[string]bool, len(versions)),
+		Unversioned: cfg.Versions.Unversioned.Enable,
+	}
+
+	for _, v := range versions {
+		docOpts.Versions[v.name] = !v.cfg.Deprecation.IsZero() || !v.cfg.Sunset.IsZero()
+	}
+
+	doc, err := openapi.Load(ctx, docOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	docHandler, err := openapi.Handler(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	authenticate := middleware.Authenticate(log, authenticator)
+	rateLimitByIP, rateLimit := initRateLimit(log, cfg.RateLimit, rdb)
+
+	r := chi.NewRouter()
+
+	r.Use(middleware.RequestID, middleware.Logger(log))
+
+	if cfg.OpenAPI.ValidateRequests || cfg.OpenAPI.ValidateResponses {
+		validator, err := middleware.OpenAPIValidator(log, doc, cfg.OpenAPI.ValidateResponses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create openapi validator: %w", err)
+		}
+
+		r.Use(validator)
+	}
+
+	// The latest version is used by the main page and is the successor of the deprecated ones.
+	latest := versions[len(versions)-1]
+	successor := basePath + "/" + latest.name
+
+	r.Get("/", handler.MainPage(successor+latest.orderPath))
+
+	api := chi.Router(r)
+	if basePath != "" {
+		api = chi.NewRouter()
+		r.Mount(basePath, api)
+	}
+
+	api.Get("/ping", handler.Ping)
+	api.Get("/openapi.json", docHandler)
+
+	for _, v := range versions {
+		next := successor
+		if v.name == latest.name {
+			next = ""
+		}
+
+		api.Route("/"+v.name, func(r chi.Router) {
+			r.Use(middleware.Deprecation(v.cfg.Deprecation, v.cfg.Sunset, next), rateLimitByIP, authenticate, rateLimit)
+
+			v.routes(r)
+		})
+	}
+
+	if cfg.Versions.Unversioned.Enable {
+		api.Group(func(r chi.Router) {
+			r.Use(middleware.Deprecation(cfg.Versions.Unversioned.Deprecation, cfg.Versions.Unversioned.Sunset, successor), rateLimitByIP, authenticate, rateLimit)
+
+			v1Routes(cfg, svc, inFlight)(r)
+		})
+	}
+
+	// Without admin credentials the admin API is not mounted rather than left open to a default token.
+	if !authenticator.CanGrant(auth.PermAdmin) {
+		log.Warn("Admin API is disabled, set http_server.admin.token_sha256 or a key with the admin role")
+	} else {
+		api.Route("/admin", func(r chi.Router) {
+			r.Use(rateLimitByIP, authenticate, rateLimit, middleware.Require(auth.PermAdmin), inFlight, middleware.Timeout(cfg.Timeout.Request))
+
+			r.Get("/consumer", handler.ConsumerState(consumer))
+			r.Get("/consumer/stats", handler.ConsumerStats(consumer))
+			r.Post("/consumer/pause", handler.PauseConsumer(consumer))
+			r.Post("/consumer/resume", handler.ResumeConsumer(consumer))
+			r.Post("/erasure", handler.ErasePersonalData(svc.ErasureService))
+		})
+	}
 
 	httpServer := server.NewHTTPServer(
 		server.WithAddr(cfg.Host, cfg.Port),
@@ -251,5 +575,5 @@ func initHTTPServer(ctx context.Context, log *zap.Logger, cfg config.HTTPServer,
 		server.WithHandler(r),
 	)
 
-	return httpServer
+	return httpServer, nil
 }
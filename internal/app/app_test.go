@@ -0,0 +1,55 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"wb-tech-test-assignment/internal/metrics"
+	"wb-tech-test-assignment/pkg/kafka"
+)
+
+type stubConsumerStats kafka.Stats
+
+func (s stubConsumerStats) Stats() kafka.Stats {
+	return kafka.Stats(s)
+}
+
+func TestMetricsRouter(t *testing.T) {
+	reg, err := metrics.NewRegistry(metrics.NewKafkaConsumerCollector(stubConsumerStats{
+		GroupID: "orders",
+		Partitions: []kafka.PartitionStats{
+			{Topic: "orders", Partition: 0, HighWaterMark: 120, MarkedOffset: 100, Lag: 20, InFlight: 2},
+		},
+		TotalLag:          20,
+		InFlight:          2,
+		MessagesReceived:  102,
+		MessagesProcessed: 100,
+	}))
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	metricsRouter(reg).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /metrics status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	body := w.Body.String()
+
+	for _, series := range []string{
+		`wb_tech_test_assignment_kafka_consumer_partition_lag{group="orders",partition="0",topic="orders"} 20`,
+		`wb_tech_test_assignment_kafka_consumer_partition_marked_offset{group="orders",partition="0",topic="orders"} 100`,
+		`wb_tech_test_assignment_kafka_consumer_in_flight_messages{group="orders"} 2`,
+		`wb_tech_test_assignment_kafka_consumer_messages_processed_total{group="orders"} 100`,
+		`wb_tech_test_assignment_kafka_consumer_messages_per_second{group="orders"} 0`,
+		"go_goroutines ",
+	} {
+		if !strings.Contains(body, series) {
+			t.Errorf("GET /metrics has no %s", series)
+		}
+	}
+}
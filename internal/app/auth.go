@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/config"
+)
+
+// initAuthenticator collects API keys from the config, the keys file and the admin token, and
+// the JWT verifier if a key set is configured.
+func initAuthenticator(log *zap.Logger, cfg config.HTTPServer) (*auth.Authenticator, error) {
+	keys := cfg.Auth.APIKeys
+
+	if cfg.Auth.APIKeysFile != "" {
+		data, err := os.ReadFile(cfg.Auth.APIKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read API keys file: %w", err)
+		}
+
+		var fileKeys []config.APIKey
+		if err = yaml.Unmarshal(data, &fileKeys); err != nil {
+			return nil, fmt.Errorf("failed to parse API keys file: %w", err)
+		}
+
+		keys = append(keys, fileKeys...)
+	}
+
+	if cfg.Admin.TokenSHA256 != "" {
+		keys = append(keys, config.APIKey{
+			Name:      "admin",
+			KeySHA256: cfg.Admin.TokenSHA256,
+			Roles:     []string{string(auth.RoleAdmin)},
+		})
+	}
+
+	opts := make([]auth.Option, 0, len(keys)+2)
+
+	for _, k := range keys {
+		key, err := auth.NewAPIKey(k.Name, k.KeySHA256, k.Roles)
+		if err != nil {
+			return nil, err
+		}
+
+		opts = append(opts, auth.WithAPIKeys(key))
+	}
+
+	if cfg.Auth.JWT.JWKSFile != "" {
+		verifier, err := auth.NewJWTVerifier(auth.JWTConfig{
+			JWKSFile:   cfg.Auth.JWT.JWKSFile,
+			Issuer:     cfg.Auth.JWT.Issuer,
+			Audience:   cfg.Auth.JWT.Audience,
+			RolesClaim: cfg.Auth.JWT.RolesClaim,
+			Leeway:     cfg.Auth.JWT.Leeway,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize JWT verifier: %w", err)
+		}
+
+		opts = append(opts, auth.WithJWT(verifier))
+	}
+
+	if !cfg.Auth.Enable {
+		log.Warn("Authentication is disabled, the public API is open with the reader role")
+
+		opts = append(opts, auth.WithAnonymousRoles(auth.RoleReader))
+	}
+
+	return auth.NewAuthenticator(opts...), nil
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/config"
+	"wb-tech-test-assignment/internal/repository"
+	"wb-tech-test-assignment/internal/service"
+	"wb-tech-test-assignment/pkg/postgres"
+	"wb-tech-test-assignment/pkg/redis"
+)
+
+// Erasure is the part of the application needed to erase personal data from the command line.
+// Running instances keep erased orders of the watch history until restart.
+type Erasure struct {
+	DB      postgres.Postgres
+	RDB     redis.Redis
+	Service *service.ErasureService
+}
+
+func NewErasure(cfg *config.Config, log *zap.Logger) (*Erasure, error) {
+	db, err := initDB(&cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	var (
+		orderRepository                           = repository.NewOrderRepository(db.Pool(), repository.ConflictPolicy(cfg.ConflictPolicy))
+		repo            service.ErasureRepository = orderRepository
+		rdb             redis.Redis
+	)
+
+	// Erased orders must be evicted from the cache used by the running instances.
+	if cfg.Redis.Enable {
+		if rdb, err = initRedis(&cfg.Redis); err != nil {
+			db.Close()
+
+			return nil, fmt.Errorf("failed to initialize redis: %w", err)
+		}
+
+		repo = repository.NewOrderWithCacheRepository(log, rdb.RDB(), orderRepository)
+	}
+
+	return &Erasure{
+		DB:      db,
+		RDB:     rdb,
+		Service: service.NewErasureService(log, repo, nil),
+	}, nil
+}
+
+func (e *Erasure) Close() error {
+	e.DB.Close()
+
+	if e.RDB != nil {
+		if err := e.RDB.Close(); err != nil {
+			return fmt.Errorf("failed to close RDB: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"math"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/api/http/middleware"
+	"wb-tech-test-assignment/internal/config"
+	"wb-tech-test-assignment/internal/ratelimit"
+	"wb-tech-test-assignment/pkg/redis"
+)
+
+// initRateLimit returns the rate limiting middlewares by IP address, used before authentication,
+// and by client, used after it. Both pass all requests if the rate is not set.
+func initRateLimit(log *zap.Logger, cfg config.RateLimit, rdb redis.Redis) (byIP, byClient middlewareFunc) {
+	if cfg.Rate <= 0 {
+		pass := func(next http.Handler) http.Handler {
+			return next
+		}
+
+		return pass, pass
+	}
+
+	burst := bucketSize(cfg.Rate, cfg.Burst)
+
+	ipRate, ipBurst := cfg.IPRate, cfg.IPBurst
+	if ipRate <= 0 {
+		ipRate = cfg.Rate
+	}
+
+	if ipBurst <= 0 && cfg.IPRate <= 0 {
+		ipBurst = burst
+	}
+
+	ipBurst = bucketSize(ipRate, ipBurst)
+
+	log.Info("Rate limiting is enabled",
+		zap.Float64("rate", cfg.Rate),
+		zap.Int("burst", burst),
+		zap.Float64("ip_rate", ipRate),
+		zap.Int("ip_burst", ipBurst),
+		zap.Bool("redis", cfg.Redis),
+	)
+
+	newLimiter := func(rate float64, burst int) ratelimit.Limiter {
+		if cfg.Redis {
+			return ratelimit.NewRedis(rdb.RDB(), rate, burst)
+		}
+
+		return ratelimit.NewLocal(rate, burst)
+	}
+
+	return middleware.RateLimitByIP(log, newLimiter(ipRate, ipBurst)), middleware.RateLimit(log, newLimiter(cfg.Rate, burst))
+}
+
+// bucketSize is one second of the rate if burst is not set.
+func bucketSize(rate float64, burst int) int {
+	if burst > 0 {
+		return burst
+	}
+
+	return max(int(math.Ceil(rate)), 1)
+}
@@ -0,0 +1,90 @@
+package app
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	"wb-tech-test-assignment/internal/api/http/handler"
+	"wb-tech-test-assignment/internal/api/http/middleware"
+	"wb-tech-test-assignment/internal/api/http/problem"
+	"wb-tech-test-assignment/internal/auth"
+	"wb-tech-test-assignment/internal/config"
+)
+
+type middlewareFunc = func(http.Handler) http.Handler
+
+// apiVersion is a version of the public API mounted under the base path with the /<name> prefix.
+type apiVersion struct {
+	name   string
+	cfg    config.APIVersion
+	routes func(r chi.Router)
+
+	// orderPath is the path of GET order without the order_uid.
+	orderPath string
+}
+
+// apiVersions returns the enabled versions from the oldest to the latest. Version 1 is mounted
+// if all versions are disabled, there would be no API otherwise. inFlight is the limit of requests
+// handled at once, shared by all versions.
+func apiVersions(cfg config.HTTPServer, svc *Service, inFlight middlewareFunc) []apiVersion {
+	all := []apiVersion{
+		{name: "v1", cfg: cfg.Versions.V1, routes: v1Routes(cfg, svc, inFlight), orderPath: "/order/"},
+		{name: "v2", cfg: cfg.Versions.V2, routes: v2Routes(cfg, svc, inFlight), orderPath: "/orders/"},
+	}
+
+	var versions []apiVersion
+
+	for _, v := range all {
+		if v.cfg.Enable {
+			versions = append(versions, v)
+		}
+	}
+
+	if len(versions) == 0 {
+		versions = all[:1]
+	}
+
+	return versions
+}
+
+func v1Routes(cfg config.HTTPServer, svc *Service, inFlight middlewareFunc) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.With(middleware.Require(auth.PermReadOrders), inFlight, middleware.Timeout(cfg.Timeout.Request)).
+			Get("/order/{orderUID}", handler.GetOrder(svc.OrderService, cfg.OrderCacheControl))
+
+		orderCollectionRoutes(r, cfg, svc, inFlight)
+	}
+}
+
+// v2Routes differs from v1Routes by GET /orders/{orderUID}, which replaces GET /order/{orderUID},
+// and by errors, which are always RFC 7807 problems. Handlers with a changed response shape are
+// registered here, the others are shared with v1.
+func v2Routes(cfg config.HTTPServer, svc *Service, inFlight middlewareFunc) func(r chi.Router) {
+	return func(r chi.Router) {
+		r.Use(problem.Prefer)
+
+		r.With(middleware.Require(auth.PermReadOrders), inFlight, middleware.Timeout(cfg.Timeout.Request)).
+			Get("/orders/{orderUID}", handler.GetOrder(svc.OrderService, cfg.OrderCacheControl))
+
+		orderCollectionRoutes(r, cfg, svc, inFlight)
+	}
+}
+
+func orderCollectionRoutes(r chi.Router, cfg config.HTTPServer, svc *Service, inFlight middlewareFunc) {
+	timeout := middleware.Timeout(cfg.Timeout.Request)
+
+	r.With(middleware.Require(auth.PermReadOrders), inFlight, timeout).
+		Get("/orders", handler.ListOrders(svc.OrderService))
+	r.With(middleware.Require(auth.PermIngestOrders), inFlight, timeout).
+		Post("/orders", handler.IngestOrders(svc.IngestService, cfg.Ingestion.MaxBatchSize))
+	r.With(middleware.Require(auth.PermReadOrders), inFlight, timeout).
+		Post("/orders/lookup", handler.LookupOrders(svc.OrderService, cfg.Lookup.MaxOrderUIDs))
+
+	// The export and the stream outlive the request timeout, they end when the client goes away.
+	// The stream is idle most of the time and is not counted in flight.
+	r.With(middleware.Require(auth.PermExportOrders), inFlight).
+		Get("/orders/export", handler.ExportOrders(svc.OrderService))
+	r.With(middleware.Require(auth.PermReadOrders)).
+		Get("/orders/stream", handler.StreamOrders(svc.OrderService, cfg.Stream.Heartbeat))
+}
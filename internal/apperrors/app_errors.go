@@ -1,10 +1,25 @@
 package apperrors
 
-import (
-	"errors"
-)
-
+// The error catalog. Codes are part of the API and must not change.
 var (
-	ErrOrderNotFound = errors.New("order not found")
-	ErrShutdown      = errors.New("shutdown error")
+	ErrOrderNotFound      = New(KindNotFound, "order_not_found", "order not found")
+	ErrOrderItemNotFound  = New(KindNotFound, "order_item_not_found", "order item not found")
+	ErrOrderAlreadyExists = New(KindConflict, "order_already_exists", "order already exists")
+	ErrOrderConflict      = New(KindConflict, "order_conflict", "order conflicts with the stored version")
+	ErrOrderErased        = New(KindConflict, "order_erased", "order personal data is erased")
+	ErrShutdown           = New(KindUnavailable, "shutdown", "shutdown error")
+	ErrInvalidCursor      = New(KindValidation, "invalid_cursor", "invalid cursor")
+	ErrInvalidErasure     = New(KindValidation, "invalid_erasure_request", "invalid erasure request")
+	ErrInvalidRequest     = New(KindValidation, "invalid_request", "invalid request")
+	ErrRequestTooLarge    = New(KindValidation, "request_too_large", "request is too large")
+	ErrRequestTimeout     = New(KindTransient, "request_timeout", "request timed out")
+	ErrUnauthorized       = New(KindUnauthorized, "unauthorized", "authentication required")
+	ErrInvalidCredentials = New(KindUnauthorized, "invalid_credentials", "invalid credentials")
+	ErrForbidden          = New(KindForbidden, "forbidden", "operation is not allowed for the client roles")
+	ErrRateLimited        = New(KindRateLimited, "rate_limited", "rate limit exceeded")
+	ErrTooManyInFlight    = New(KindRateLimited, "too_many_in_flight", "too many requests in progress")
+	ErrOrderPublish       = New(KindTransient, "order_publish_failed", "failed to publish order")
+
+	ErrOrderDecode     = New(KindValidation, "order_decode", "order decode error")
+	ErrOrderValidation = New(KindValidation, "order_validation", "order validation error")
 )
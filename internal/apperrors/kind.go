@@ -0,0 +1,108 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Kind defines how an error is handled: the HTTP status and whether the operation is retried.
+type Kind string
+
+const (
+	// KindNotFound means the requested entity does not exist.
+	KindNotFound Kind = "not_found"
+
+	// KindValidation means the input is malformed or breaks a rule, repeating it does not help.
+	KindValidation Kind = "validation"
+
+	// KindConflict means the input contradicts the stored state.
+	KindConflict Kind = "conflict"
+
+	// KindUnauthorized means the client is not authenticated or its credentials are invalid.
+	KindUnauthorized Kind = "unauthorized"
+
+	// KindForbidden means the client is authenticated but its roles do not allow the operation.
+	KindForbidden Kind = "forbidden"
+
+	// KindRateLimited means the client sends too many requests, it may repeat the operation later.
+	KindRateLimited Kind = "rate_limited"
+
+	// KindTransient means a temporary failure of a dependency, the operation may succeed if repeated.
+	KindTransient Kind = "transient"
+
+	// KindUnavailable means the service can not handle the operation now, for example it is shutting down.
+	KindUnavailable Kind = "unavailable"
+
+	// KindInternal is any other error, its details are not shown to clients.
+	KindInternal Kind = "internal"
+)
+
+// Error is an error of the catalog. Code is a stable machine-readable identifier for clients,
+// Message is safe to show to them.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches errors by code, so errors made by Errorf match the catalog error they come from.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+
+	return ok && t.Code == e.Code
+}
+
+// Errorf returns an error of the same kind and code with a more specific message.
+func (e *Error) Errorf(format string, args ...any) *Error {
+	return New(e.Kind, e.Code, fmt.Sprintf(format, args...))
+}
+
+// KindOf classifies err. Errors of the catalog keep their kind, failures of the storage and
+// the network are transient, a cancelled context is unavailable, everything else is internal.
+func KindOf(err error) Kind {
+	if err == nil {
+		return ""
+	}
+
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Kind
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return KindUnavailable
+	case isTransient(err):
+		return KindTransient
+	default:
+		return KindInternal
+	}
+}
+
+// CodeOf returns the code of the catalog error wrapped by err, or the kind for other errors.
+func CodeOf(err error) string {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+
+	return string(KindOf(err))
+}
+
+// IsRetryable reports whether the operation failed with err may succeed if repeated.
+func IsRetryable(err error) bool {
+	return KindOf(err) == KindTransient
+}
@@ -0,0 +1,53 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/redis/go-redis/v9"
+)
+
+// Postgres error codes that are worth retrying, see https://www.postgresql.org/docs/current/errcodes-appendix.html.
+var transientPgCodes = map[string]struct{}{
+	"40001": {}, // serialization_failure
+	"40P01": {}, // deadlock_detected
+	"55P03": {}, // lock_not_available
+	"53300": {}, // too_many_connections
+	"57P01": {}, // admin_shutdown
+	"57P02": {}, // crash_shutdown
+	"57P03": {}, // cannot_connect_now
+}
+
+// isTransient reports whether err is caused by a temporary storage failure
+// (lost connection, serialization failure, deadlock, pool timeout).
+func isTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if _, ok := transientPgCodes[pgErr.Code]; ok {
+			return true
+		}
+
+		// Class 08 - Connection Exception.
+		return len(pgErr.Code) == 5 && pgErr.Code[:2] == "08"
+	}
+
+	if pgconn.SafeToRetry(err) || pgconn.Timeout(err) {
+		return true
+	}
+
+	if errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, context.DeadlineExceeded) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,81 @@
+package apperrors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+		kind Kind
+	}{
+		{name: "serialization failure", err: &pgconn.PgError{Code: "40001"}, want: true, kind: KindTransient},
+		{name: "deadlock", err: &pgconn.PgError{Code: "40P01"}, want: true, kind: KindTransient},
+		{name: "lock not available", err: &pgconn.PgError{Code: "55P03"}, want: true, kind: KindTransient},
+		{name: "too many connections", err: &pgconn.PgError{Code: "53300"}, want: true, kind: KindTransient},
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true, kind: KindTransient},
+		{name: "wrapped connection exception", err: fmt.Errorf("failed to insert order: %w", &pgconn.PgError{Code: "08001"}), want: true, kind: KindTransient},
+		{name: "net error", err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("no route to host")}, want: true, kind: KindTransient},
+		{name: "redis pool timeout", err: fmt.Errorf("failed to get order: %w", redis.ErrPoolTimeout), want: true, kind: KindTransient},
+		{name: "connection refused", err: fmt.Errorf("dial: %w", syscall.ECONNREFUSED), want: true, kind: KindTransient},
+		{name: "connection reset", err: syscall.ECONNRESET, want: true, kind: KindTransient},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true, kind: KindTransient},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: true, kind: KindTransient},
+		{name: "catalog transient error", err: ErrOrderPublish, want: true, kind: KindTransient},
+
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505", ConstraintName: "orders_pkey"}, kind: KindInternal},
+		{name: "check violation", err: fmt.Errorf("failed to insert item: %w", &pgconn.PgError{Code: "23514"}), kind: KindInternal},
+		{name: "invalid text representation", err: &pgconn.PgError{Code: "22P02"}, kind: KindInternal},
+		{name: "validation error", err: fmt.Errorf("failed to validate: %w", ErrOrderValidation), kind: KindValidation},
+		{name: "conflict", err: ErrOrderConflict, kind: KindConflict},
+		{name: "canceled", err: context.Canceled, kind: KindUnavailable},
+		{name: "shutdown", err: ErrShutdown, kind: KindUnavailable},
+		{name: "plain error", err: errors.New("boom"), kind: KindInternal},
+		{name: "nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable() = %v, want %v", got, tt.want)
+			}
+
+			if got := KindOf(tt.err); got != tt.kind {
+				t.Errorf("KindOf() = %q, want %q", got, tt.kind)
+			}
+		})
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "catalog error", err: ErrOrderNotFound, want: "order_not_found"},
+		{name: "specific message", err: ErrInvalidRequest.Errorf("limit must be positive"), want: "invalid_request"},
+		{name: "wrapped", err: fmt.Errorf("%w: %w", ErrOrderPublish, errors.New("broker down")), want: "order_publish_failed"},
+		{name: "storage failure", err: &pgconn.PgError{Code: "40001"}, want: "transient"},
+		{name: "unknown", err: errors.New("boom"), want: "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CodeOf(tt.err); got != tt.want {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// Package auth authenticates clients of the HTTP API by API keys and JWT and checks their roles.
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+// Role is granted to a client by its API key or by a claim of its token. Roles are ordered,
+// every role has the permissions of the previous ones.
+type Role string
+
+const (
+	// RoleReader reads orders without personal data of customers.
+	RoleReader Role = "reader"
+
+	// RoleSupport reads orders with personal data, exports and ingests them.
+	RoleSupport Role = "support"
+
+	// RoleAdmin manages the service through the admin API.
+	RoleAdmin Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleReader:  1,
+	RoleSupport: 2,
+	RoleAdmin:   3,
+}
+
+// Valid reports whether the role is known.
+func (r Role) Valid() bool {
+	_, ok := roleLevels[r]
+
+	return ok
+}
+
+// Permission is an operation gated by roles.
+type Permission int
+
+const (
+	PermReadOrders Permission = iota
+	PermReadPersonalData
+	PermExportOrders
+	PermIngestOrders
+	PermAdmin
+)
+
+// minRoles is the lowest role having the permission.
+var minRoles = map[Permission]Role{
+	PermReadOrders:       RoleReader,
+	PermReadPersonalData: RoleSupport,
+	PermExportOrders:     RoleSupport,
+	PermIngestOrders:     RoleSupport,
+	PermAdmin:            RoleAdmin,
+}
+
+// Authentication methods of a principal.
+const (
+	MethodAnonymous = "anonymous"
+	MethodAPIKey    = "api_key"
+	MethodJWT       = "jwt"
+)
+
+// Principal is the client of a request.
+type Principal struct {
+	// Subject is the name of the API key or the subject of the token.
+	Subject string
+	Method  string
+	Roles   []Role
+}
+
+// Anonymous is the principal of requests without credentials.
+func Anonymous(roles ...Role) Principal {
+	return Principal{Method: MethodAnonymous, Roles: roles}
+}
+
+// Can reports whether any of the roles of the principal has the permission.
+func (p Principal) Can(perm Permission) bool {
+	required, ok := minRoles[perm]
+	if !ok {
+		return false
+	}
+
+	return slices.ContainsFunc(p.Roles, func(r Role) bool {
+		return roleLevels[r] >= roleLevels[required]
+	})
+}
+
+type contextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// FromContext returns the principal of the request, an anonymous principal without roles if there is none.
+func FromContext(ctx context.Context) Principal {
+	if p, ok := ctx.Value(contextKey{}).(Principal); ok {
+		return p
+	}
+
+	return Anonymous()
+}
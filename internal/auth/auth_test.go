@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"testing"
+)
+
+func TestPrincipalCan(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles []Role
+		perm  Permission
+		want  bool
+	}{
+		{name: "no roles", perm: PermReadOrders, want: false},
+		{name: "reader reads", roles: []Role{RoleReader}, perm: PermReadOrders, want: true},
+		{name: "reader without personal data", roles: []Role{RoleReader}, perm: PermReadPersonalData, want: false},
+		{name: "support reads personal data", roles: []Role{RoleSupport}, perm: PermReadPersonalData, want: true},
+		{name: "support ingests", roles: []Role{RoleSupport}, perm: PermIngestOrders, want: true},
+		{name: "support is not admin", roles: []Role{RoleSupport}, perm: PermAdmin, want: false},
+		{name: "admin has lower permissions", roles: []Role{RoleAdmin}, perm: PermExportOrders, want: true},
+		{name: "highest of several roles", roles: []Role{RoleReader, RoleAdmin}, perm: PermAdmin, want: true},
+		{name: "unknown role", roles: []Role{"owner"}, perm: PermReadOrders, want: false},
+		{name: "unknown permission", roles: []Role{RoleAdmin}, perm: Permission(100), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Principal{Roles: tt.roles}).Can(tt.perm); got != tt.want {
+				t.Errorf("Can() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if p := FromContext(context.Background()); p.Method != MethodAnonymous || len(p.Roles) != 0 {
+		t.Errorf("FromContext() without principal = %+v, want anonymous without roles", p)
+	}
+
+	want := Principal{Subject: "svc", Method: MethodAPIKey, Roles: []Role{RoleSupport}}
+
+	if p := FromContext(WithPrincipal(context.Background(), want)); p.Subject != want.Subject || !slices.Equal(p.Roles, want.Roles) {
+		t.Errorf("FromContext() = %+v, want %+v", p, want)
+	}
+}
+
+func keySHA256(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func TestNewAPIKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		hash    string
+		roles   []string
+		wantErr bool
+	}{
+		{name: "valid", hash: keySHA256("secret"), roles: []string{"reader", " support "}},
+		{name: "no roles", hash: keySHA256("secret")},
+		{name: "not hex", hash: "not-a-hash", roles: []string{"reader"}, wantErr: true},
+		{name: "short hash", hash: "abcd", roles: []string{"reader"}, wantErr: true},
+		{name: "unknown role", hash: keySHA256("secret"), roles: []string{"owner"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAPIKey("client", tt.hash, tt.roles)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewAPIKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func mustAPIKey(t *testing.T, name, key string, roles ...string) APIKey {
+	t.Helper()
+
+	k, err := NewAPIKey(name, keySHA256(key), roles)
+	if err != nil {
+		t.Fatalf("NewAPIKey() error = %v", err)
+	}
+
+	return k
+}
+
+func TestAuthenticatorAPIKey(t *testing.T) {
+	a := NewAuthenticator(WithAPIKeys(
+		mustAPIKey(t, "dashboard", "reader-key", "reader"),
+		mustAPIKey(t, "support", "support-key", "support"),
+	))
+
+	tests := []struct {
+		name        string
+		key         string
+		wantOK      bool
+		wantSubject string
+		wantRole    Role
+	}{
+		{name: "reader", key: "reader-key", wantOK: true, wantSubject: "dashboard", wantRole: RoleReader},
+		{name: "support", key: "support-key", wantOK: true, wantSubject: "support", wantRole: RoleSupport},
+		{name: "unknown", key: "other-key"},
+		{name: "prefix of a key", key: "reader"},
+		{name: "empty", key: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, ok := a.APIKey(tt.key)
+			if ok != tt.wantOK {
+				t.Fatalf("APIKey() ok = %v, want %v", ok, tt.wantOK)
+			}
+
+			if !ok {
+				return
+			}
+
+			if p.Subject != tt.wantSubject || p.Method != MethodAPIKey || !slices.Equal(p.Roles, []Role{tt.wantRole}) {
+				t.Errorf("APIKey() = %+v, want subject %q with role %s", p, tt.wantSubject, tt.wantRole)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorBearerAPIKey(t *testing.T) {
+	a := NewAuthenticator(WithAPIKeys(mustAPIKey(t, "dashboard", "reader-key", "reader")))
+
+	if p, err := a.Bearer("reader-key"); err != nil || p.Subject != "dashboard" {
+		t.Errorf("Bearer() = %+v, %v, want the dashboard key", p, err)
+	}
+
+	// Without a JWT verifier a token of three segments is looked up as a key.
+	if _, err := a.Bearer("a.b.c"); err == nil {
+		t.Error("Bearer() of an unknown token succeeded")
+	}
+}
+
+func TestAuthenticatorCanGrant(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		perm Permission
+		want bool
+	}{
+		{name: "no keys", perm: PermAdmin, want: false},
+		{
+			name: "key with the role",
+			opts: []Option{WithAPIKeys(mustAPIKey(t, "ops", "admin-key", "admin"))},
+			perm: PermAdmin,
+			want: true,
+		},
+		{
+			name: "keys below the role",
+			opts: []Option{WithAPIKeys(mustAPIKey(t, "support", "support-key", "support"))},
+			perm: PermAdmin,
+			want: false,
+		},
+		{
+			name: "anonymous reader",
+			opts: []Option{WithAnonymousRoles(RoleReader)},
+			perm: PermReadOrders,
+			want: true,
+		},
+		{
+			name: "tokens",
+			opts: []Option{WithJWT(&JWTVerifier{})},
+			perm: PermAdmin,
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewAuthenticator(tt.opts...).CanGrant(tt.perm); got != tt.want {
+				t.Errorf("CanGrant() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
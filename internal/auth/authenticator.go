@@ -0,0 +1,142 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKey is a static key of a client. Only SHA-256 of the key is stored.
+type APIKey struct {
+	Name  string
+	Hash  [sha256.Size]byte
+	Roles []Role
+}
+
+// NewAPIKey parses the hex encoded SHA-256 of the key and the roles.
+func NewAPIKey(name, keySHA256 string, roles []string) (APIKey, error) {
+	hash, err := hex.DecodeString(strings.TrimSpace(keySHA256))
+	if err != nil || len(hash) != sha256.Size {
+		return APIKey{}, fmt.Errorf("invalid SHA-256 of API key %q", name)
+	}
+
+	key := APIKey{Name: name}
+	copy(key.Hash[:], hash)
+
+	if key.Roles, err = parseRoles(roles); err != nil {
+		return APIKey{}, fmt.Errorf("invalid API key %q: %w", name, err)
+	}
+
+	return key, nil
+}
+
+// Authenticator finds the principal of a request by its API key or bearer token.
+type Authenticator struct {
+	keys      []APIKey
+	jwt       *JWTVerifier
+	anonymous Principal
+}
+
+type Option func(*Authenticator)
+
+// WithAPIKeys adds static API keys.
+func WithAPIKeys(keys ...APIKey) Option {
+	return func(a *Authenticator) {
+		a.keys = append(a.keys, keys...)
+	}
+}
+
+// WithJWT enables bearer tokens verified by v.
+func WithJWT(v *JWTVerifier) Option {
+	return func(a *Authenticator) {
+		a.jwt = v
+	}
+}
+
+// WithAnonymousRoles grants roles to requests without credentials.
+func WithAnonymousRoles(roles ...Role) Option {
+	return func(a *Authenticator) {
+		a.anonymous = Anonymous(roles...)
+	}
+}
+
+func NewAuthenticator(opts ...Option) *Authenticator {
+	a := &Authenticator{anonymous: Anonymous()}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// Anonymous returns the principal of requests without credentials.
+func (a *Authenticator) Anonymous() Principal {
+	return a.anonymous
+}
+
+// CanGrant reports whether some client may have the permission: a key has it or tokens are accepted,
+// whose roles are not known in advance.
+func (a *Authenticator) CanGrant(perm Permission) bool {
+	if a.jwt != nil {
+		return true
+	}
+
+	for _, key := range a.keys {
+		if (Principal{Roles: key.Roles}).Can(perm) {
+			return true
+		}
+	}
+
+	return a.anonymous.Can(perm)
+}
+
+// APIKey returns the principal of the key. Keys are compared in constant time.
+func (a *Authenticator) APIKey(key string) (Principal, bool) {
+	sum := sha256.Sum256([]byte(key))
+
+	var found *APIKey
+
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(sum[:], a.keys[i].Hash[:]) == 1 {
+			found = &a.keys[i]
+		}
+	}
+
+	if found == nil {
+		return Principal{}, false
+	}
+
+	return Principal{Subject: found.Name, Method: MethodAPIKey, Roles: found.Roles}, true
+}
+
+// Bearer returns the principal of a bearer token, which is either a JWT or an API key.
+func (a *Authenticator) Bearer(token string) (Principal, error) {
+	if a.jwt != nil && strings.Count(token, ".") == 2 {
+		return a.jwt.Verify(token)
+	}
+
+	p, ok := a.APIKey(token)
+	if !ok {
+		return Principal{}, fmt.Errorf("unknown API key")
+	}
+
+	return p, nil
+}
+
+func parseRoles(names []string) ([]Role, error) {
+	roles := make([]Role, 0, len(names))
+
+	for _, name := range names {
+		role := Role(strings.TrimSpace(name))
+		if !role.Valid() {
+			return nil, fmt.Errorf("unknown role %q", name)
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const defaultRolesClaim = "roles"
+
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+type JWTConfig struct {
+	// JWKSFile is the path of a JSON Web Key Set with the public keys of the issuer.
+	JWKSFile string
+	Issuer   string
+	Audience string
+
+	// RolesClaim is the claim with the roles, an array of strings or a space-separated string.
+	RolesClaim string
+
+	// Leeway is the allowed clock skew.
+	Leeway time.Duration
+}
+
+// JWTVerifier verifies signed tokens against a local key set. Tokens must have exp,
+// iss and aud are checked if configured.
+type JWTVerifier struct {
+	keys jose.JSONWebKeySet
+	cfg  JWTConfig
+}
+
+func NewJWTVerifier(cfg JWTConfig) (*JWTVerifier, error) {
+	data, err := os.ReadFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	v := &JWTVerifier{cfg: cfg}
+
+	if err = json.Unmarshal(data, &v.keys); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS file: %w", err)
+	}
+
+	if len(v.keys.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s has no keys", cfg.JWKSFile)
+	}
+
+	for _, key := range v.keys.Keys {
+		if !key.IsPublic() {
+			return nil, fmt.Errorf("JWKS file %s has a private key %q", cfg.JWKSFile, key.KeyID)
+		}
+	}
+
+	if v.cfg.RolesClaim == "" {
+		v.cfg.RolesClaim = defaultRolesClaim
+	}
+
+	return v, nil
+}
+
+// Verify checks the signature and the claims of the token and returns its principal.
+// Unknown roles in the token are ignored.
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	parsed, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return Principal{}, fmt.Errorf("failed to parse token: %w", err)
+	}
+
+	key, err := v.key(parsed)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	var (
+		claims jwt.Claims
+		custom map[string]any
+	)
+
+	if err = parsed.Claims(key.Key, &claims, &custom); err != nil {
+		return Principal{}, fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	if claims.Expiry == nil {
+		return Principal{}, fmt.Errorf("token has no exp")
+	}
+
+	expected := jwt.Expected{Issuer: v.cfg.Issuer}
+	if v.cfg.Audience != "" {
+		expected.AnyAudience = jwt.Audience{v.cfg.Audience}
+	}
+
+	if err = claims.ValidateWithLeeway(expected, v.cfg.Leeway); err != nil {
+		return Principal{}, fmt.Errorf("invalid token claims: %w", err)
+	}
+
+	return Principal{
+		Subject: claims.Subject,
+		Method:  MethodJWT,
+		Roles:   tokenRoles(custom[v.cfg.RolesClaim]),
+	}, nil
+}
+
+// key finds the key by the kid of the token, a token without kid is accepted only if the set has a single key.
+func (v *JWTVerifier) key(token *jwt.JSONWebToken) (jose.JSONWebKey, error) {
+	if len(token.Headers) == 0 {
+		return jose.JSONWebKey{}, fmt.Errorf("token has no header")
+	}
+
+	header := token.Headers[0]
+
+	var key jose.JSONWebKey
+
+	switch keys := v.keys.Key(header.KeyID); {
+	case header.KeyID == "" && len(v.keys.Keys) == 1:
+		key = v.keys.Keys[0]
+	case header.KeyID == "":
+		return jose.JSONWebKey{}, fmt.Errorf("token has no kid")
+	case len(keys) == 0:
+		return jose.JSONWebKey{}, fmt.Errorf("unknown kid %q", header.KeyID)
+	default:
+		key = keys[0]
+	}
+
+	// A key bound to an algorithm is not used with another one.
+	if key.Algorithm != "" && key.Algorithm != header.Algorithm {
+		return jose.JSONWebKey{}, fmt.Errorf("token algorithm %s does not match key %q", header.Algorithm, key.KeyID)
+	}
+
+	return key, nil
+}
+
+func tokenRoles(claim any) []Role {
+	var names []string
+
+	switch c := claim.(type) {
+	case string:
+		names = strings.Fields(c)
+	case []any:
+		for _, v := range c {
+			if s, ok := v.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+
+	roles := make([]Role, 0, len(names))
+
+	for _, name := range names {
+		if role := Role(name); role.Valid() {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
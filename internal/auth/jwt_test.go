@@ -0,0 +1,316 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+type testKey struct {
+	id      string
+	private *ecdsa.PrivateKey
+}
+
+func newTestKey(t *testing.T, id string) testKey {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	return testKey{id: id, private: private}
+}
+
+// writeJWKS writes the public keys to a JWKS file, alg binds the keys to an algorithm if set.
+func writeJWKS(t *testing.T, alg string, keys ...testKey) string {
+	t.Helper()
+
+	var set jose.JSONWebKeySet
+
+	for _, k := range keys {
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       k.private.Public(),
+			KeyID:     k.id,
+			Algorithm: alg,
+			Use:       "sig",
+		})
+	}
+
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal JWKS: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write JWKS: %v", err)
+	}
+
+	return path
+}
+
+func sign(t *testing.T, key crypto.Signer, kid string, claims any) string {
+	t.Helper()
+
+	opts := &jose.SignerOptions{}
+	if kid != "" {
+		opts = opts.WithHeader(jose.HeaderKey("kid"), kid)
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, opts.WithType("JWT"))
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+
+	return token
+}
+
+type tokenClaims struct {
+	jwt.Claims
+
+	Roles any `json:"roles,omitempty"`
+}
+
+func TestJWTVerifierVerify(t *testing.T) {
+	key := newTestKey(t, "k1")
+	other := newTestKey(t, "k2")
+	stranger := newTestKey(t, "k1")
+
+	now := time.Now()
+
+	valid := func() tokenClaims {
+		return tokenClaims{
+			Claims: jwt.Claims{
+				Subject:  "alice",
+				Issuer:   "https://issuer.example",
+				Audience: jwt.Audience{"orders"},
+				Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+			},
+			Roles: []string{"support", "unknown"},
+		}
+	}
+
+	with := func(change func(*tokenClaims)) tokenClaims {
+		c := valid()
+		change(&c)
+
+		return c
+	}
+
+	twoKeys := writeJWKS(t, "", key, other)
+
+	tests := []struct {
+		name      string
+		jwks      string
+		token     string
+		wantErr   bool
+		wantRoles []Role
+	}{
+		{
+			name:      "valid",
+			jwks:      twoKeys,
+			token:     sign(t, key.private, "k1", valid()),
+			wantRoles: []Role{RoleSupport},
+		},
+		{
+			name:      "second key",
+			jwks:      twoKeys,
+			token:     sign(t, other.private, "k2", valid()),
+			wantRoles: []Role{RoleSupport},
+		},
+		{
+			name:      "roles as a string",
+			jwks:      twoKeys,
+			token:     sign(t, key.private, "k1", with(func(c *tokenClaims) { c.Roles = "reader admin" })),
+			wantRoles: []Role{RoleReader, RoleAdmin},
+		},
+		{
+			name:      "no roles",
+			jwks:      twoKeys,
+			token:     sign(t, key.private, "k1", with(func(c *tokenClaims) { c.Roles = nil })),
+			wantRoles: []Role{},
+		},
+		{
+			name:    "unknown kid",
+			jwks:    twoKeys,
+			token:   sign(t, key.private, "k3", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "no kid with several keys",
+			jwks:    twoKeys,
+			token:   sign(t, key.private, "", valid()),
+			wantErr: true,
+		},
+		{
+			name:      "no kid with a single key",
+			jwks:      writeJWKS(t, "", key),
+			token:     sign(t, key.private, "", valid()),
+			wantRoles: []Role{RoleSupport},
+		},
+		{
+			name:    "signed by another key",
+			jwks:    twoKeys,
+			token:   sign(t, stranger.private, "k1", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "key bound to another algorithm",
+			jwks:    writeJWKS(t, string(jose.ES384), key),
+			token:   sign(t, key.private, "k1", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			jwks:    twoKeys,
+			token:   sign(t, key.private, "k1", with(func(c *tokenClaims) { c.Expiry = jwt.NewNumericDate(now.Add(-time.Hour)) })),
+			wantErr: true,
+		},
+		{
+			name:      "expired within leeway",
+			jwks:      twoKeys,
+			token:     sign(t, key.private, "k1", with(func(c *tokenClaims) { c.Expiry = jwt.NewNumericDate(now.Add(-10 * time.Second)) })),
+			wantRoles: []Role{RoleSupport},
+		},
+		{
+			name:    "no exp",
+			jwks:    twoKeys,
+			token:   sign(t, key.private, "k1", with(func(c *tokenClaims) { c.Expiry = nil })),
+			wantErr: true,
+		},
+		{
+			name:    "not yet valid",
+			jwks:    twoKeys,
+			token:   sign(t, key.private, "k1", with(func(c *tokenClaims) { c.NotBefore = jwt.NewNumericDate(now.Add(time.Hour)) })),
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			jwks:    twoKeys,
+			token:   sign(t, key.private, "k1", with(func(c *tokenClaims) { c.Issuer = "https://other.example" })),
+			wantErr: true,
+		},
+		{
+			name:    "wrong audience",
+			jwks:    twoKeys,
+			token:   sign(t, key.private, "k1", with(func(c *tokenClaims) { c.Audience = jwt.Audience{"billing"} })),
+			wantErr: true,
+		},
+		{
+			name:    "malformed",
+			jwks:    twoKeys,
+			token:   "a.b.c",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewJWTVerifier(JWTConfig{
+				JWKSFile: tt.jwks,
+				Issuer:   "https://issuer.example",
+				Audience: "orders",
+				Leeway:   time.Minute,
+			})
+			if err != nil {
+				t.Fatalf("NewJWTVerifier() error = %v", err)
+			}
+
+			p, err := v.Verify(tt.token)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				return
+			}
+
+			if p.Subject != "alice" || p.Method != MethodJWT || !slices.Equal(p.Roles, tt.wantRoles) {
+				t.Errorf("Verify() = %+v, want alice with roles %v", p, tt.wantRoles)
+			}
+		})
+	}
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	key := newTestKey(t, "k1")
+
+	private, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key.private, KeyID: "k1"}}})
+	if err != nil {
+		t.Fatalf("failed to marshal JWKS: %v", err)
+	}
+
+	write := func(data string) string {
+		path := filepath.Join(t.TempDir(), "jwks.json")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatalf("failed to write JWKS: %v", err)
+		}
+
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "public keys", path: writeJWKS(t, "", key)},
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing.json"), wantErr: true},
+		{name: "invalid JSON", path: write("{"), wantErr: true},
+		{name: "no keys", path: write(`{"keys":[]}`), wantErr: true},
+		{name: "private key", path: write(string(private)), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := NewJWTVerifier(JWTConfig{JWKSFile: tt.path})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewJWTVerifier() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && v.cfg.RolesClaim != defaultRolesClaim {
+				t.Errorf("RolesClaim = %q, want %q", v.cfg.RolesClaim, defaultRolesClaim)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorBearerJWT(t *testing.T) {
+	key := newTestKey(t, "k1")
+
+	v, err := NewJWTVerifier(JWTConfig{JWKSFile: writeJWKS(t, "", key)})
+	if err != nil {
+		t.Fatalf("NewJWTVerifier() error = %v", err)
+	}
+
+	a := NewAuthenticator(WithJWT(v), WithAPIKeys(mustAPIKey(t, "dashboard", "reader-key", "reader")))
+
+	token := sign(t, key.private, "k1", tokenClaims{
+		Claims: jwt.Claims{Subject: "alice", Expiry: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+		Roles:  []string{"admin"},
+	})
+
+	if p, err := a.Bearer(token); err != nil || p.Method != MethodJWT || !p.Can(PermAdmin) {
+		t.Errorf("Bearer() of a token = %+v, %v, want an admin principal", p, err)
+	}
+
+	if p, err := a.Bearer("reader-key"); err != nil || p.Method != MethodAPIKey {
+		t.Errorf("Bearer() of a key = %+v, %v, want the dashboard key", p, err)
+	}
+}
@@ -0,0 +1,120 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hamba/avro/v2"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+const (
+	// Avro messages use the Confluent wire format: a zero magic byte, a 4 byte big-endian schema id and the Avro binary body.
+	avroMagicByte  = 0
+	avroHeaderSize = 5
+
+	AvroOrderSchemaName      = "orders.v1.Order"
+	AvroOrderEventSchemaName = "orders.v1.OrderEvent"
+)
+
+var ErrInvalidAvroMessage = errors.New("invalid avro message")
+
+// Field names of the Avro schemas match the JSON names of the model.
+var avroAPI = avro.Config{TagKey: "json"}.Freeze()
+
+type avroOrderEvent struct {
+	EventID          string                   `json:"event_id"`
+	EventType        string                   `json:"event_type"`
+	OccurredAt       time.Time                `json:"occurred_at"`
+	Order            *model.Order             `json:"order"`
+	ItemStatusChange *model.ItemStatusChange  `json:"item_status_change"`
+	Cancellation     *model.OrderCancellation `json:"cancellation"`
+}
+
+type avroCodec struct {
+	registry SchemaRegistry
+}
+
+func NewAvro(registry SchemaRegistry) Codec {
+	return avroCodec{registry: registry}
+}
+
+func (avroCodec) Name() string {
+	return NameAvro
+}
+
+func (avroCodec) ContentTypes() []string {
+	return []string{"application/avro", "avro/binary"}
+}
+
+// Decode accepts records of the orders.v1.OrderEvent schema and bare orders.v1.Order records,
+// which are treated as order.created.
+func (c avroCodec) Decode(data []byte) (model.OrderEvent, error) {
+	if len(data) < avroHeaderSize || data[0] != avroMagicByte {
+		return model.OrderEvent{}, fmt.Errorf("%w: missing schema id header", ErrInvalidAvroMessage)
+	}
+
+	id := int(binary.BigEndian.Uint32(data[1:avroHeaderSize]))
+
+	schema, err := c.registry.Schema(id)
+	if err != nil {
+		return model.OrderEvent{}, err
+	}
+
+	named, ok := schema.(avro.NamedSchema)
+	if !ok {
+		return model.OrderEvent{}, fmt.Errorf("%w: schema %d is not a record", ErrInvalidAvroMessage, id)
+	}
+
+	body := data[avroHeaderSize:]
+
+	switch named.FullName() {
+	case AvroOrderSchemaName:
+		var order model.Order
+		if err := avroAPI.Unmarshal(schema, body, &order); err != nil {
+			return model.OrderEvent{}, fmt.Errorf("failed to unmarshal avro order: %w", err)
+		}
+
+		order.ClearStoredFields()
+
+		return newAvroEvent(derivedEventID(data), string(model.EventOrderCreated), time.Now(), order)
+	case AvroOrderEventSchemaName:
+		var event avroOrderEvent
+		if err := avroAPI.Unmarshal(schema, body, &event); err != nil {
+			return model.OrderEvent{}, fmt.Errorf("failed to unmarshal avro event: %w", err)
+		}
+
+		switch {
+		case event.Order != nil:
+			event.Order.ClearStoredFields()
+
+			return newAvroEvent(event.EventID, event.EventType, event.OccurredAt, event.Order)
+		case event.ItemStatusChange != nil:
+			return newAvroEvent(event.EventID, event.EventType, event.OccurredAt, event.ItemStatusChange)
+		case event.Cancellation != nil:
+			return newAvroEvent(event.EventID, event.EventType, event.OccurredAt, event.Cancellation)
+		default:
+			return model.OrderEvent{}, fmt.Errorf("%w: avro event %q has no payload", ErrInvalidAvroMessage, event.EventID)
+		}
+	default:
+		return model.OrderEvent{}, fmt.Errorf("%w: unsupported schema %q", ErrInvalidAvroMessage, named.FullName())
+	}
+}
+
+func newAvroEvent(id, eventType string, occurredAt time.Time, payload any) (model.OrderEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return model.OrderEvent{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return model.OrderEvent{
+		EventID:    id,
+		EventType:  model.EventType(eventType),
+		OccurredAt: occurredAt,
+		Payload:    data,
+	}, nil
+}
@@ -0,0 +1,154 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+const (
+	avroOrderSchemaID = 1
+	avroEventSchemaID = 2
+)
+
+func newTestAvro() Codec {
+	return NewAvro(NewFileSchemaRegistry("../../schemas/avro"))
+}
+
+// encodeAvro encodes v with the schema of the id in the Confluent wire format.
+func encodeAvro(t *testing.T, id int, v any) []byte {
+	t.Helper()
+
+	schema, err := NewFileSchemaRegistry("../../schemas/avro").Schema(id)
+	if err != nil {
+		t.Fatalf("failed to load schema %d: %v", id, err)
+	}
+
+	body, err := avroAPI.Marshal(schema, v)
+	if err != nil {
+		t.Fatalf("failed to marshal avro: %v", err)
+	}
+
+	header := make([]byte, avroHeaderSize)
+	header[0] = avroMagicByte
+	binary.BigEndian.PutUint32(header[1:], uint32(id))
+
+	return append(header, body...)
+}
+
+func TestAvroDecodeOrder(t *testing.T) {
+	data := encodeAvro(t, avroOrderSchemaID, sampleOrder())
+
+	event, err := newTestAvro().Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if event.EventType != model.EventOrderCreated || !strings.HasPrefix(event.EventID, "derived-") {
+		t.Errorf("Decode() = %+v, want order.created with a derived id", event)
+	}
+
+	assertPayload(t, event, sampleOrder())
+}
+
+func TestAvroDecodeEvent(t *testing.T) {
+	occurredAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	order := sampleOrder()
+
+	tests := []struct {
+		name  string
+		event avroOrderEvent
+		check func(t *testing.T, event model.OrderEvent)
+	}{
+		{
+			name:  "order",
+			event: avroOrderEvent{EventType: string(model.EventOrderUpdated), Order: &order},
+			check: func(t *testing.T, event model.OrderEvent) {
+				assertPayload(t, event, sampleOrder())
+			},
+		},
+		{
+			name: "item status change",
+			event: avroOrderEvent{
+				EventType:        string(model.EventOrderItemStatusChanged),
+				ItemStatusChange: &model.ItemStatusChange{OrderUID: "uid", ChrtID: 9934930, Status: 300},
+			},
+			check: func(t *testing.T, event model.OrderEvent) {
+				assertPayload(t, event, model.ItemStatusChange{OrderUID: "uid", ChrtID: 9934930, Status: 300})
+			},
+		},
+		{
+			name: "cancellation",
+			event: avroOrderEvent{
+				EventType:    string(model.EventOrderCancelled),
+				Cancellation: &model.OrderCancellation{OrderUID: "uid", Reason: "fraud"},
+			},
+			check: func(t *testing.T, event model.OrderEvent) {
+				assertPayload(t, event, model.OrderCancellation{OrderUID: "uid", Reason: "fraud"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.EventID = "evt-" + tt.name
+			tt.event.OccurredAt = occurredAt
+
+			event, err := newTestAvro().Decode(encodeAvro(t, avroEventSchemaID, tt.event))
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if event.EventID != tt.event.EventID || string(event.EventType) != tt.event.EventType || !event.OccurredAt.Equal(occurredAt) {
+				t.Errorf("Decode() = %+v, want %s of type %s at %s", event, tt.event.EventID, tt.event.EventType, occurredAt)
+			}
+
+			tt.check(t, event)
+		})
+	}
+}
+
+func TestAvroDecodeInvalid(t *testing.T) {
+	valid := encodeAvro(t, avroOrderSchemaID, sampleOrder())
+
+	withSchemaID := func(id uint32) []byte {
+		data := append([]byte(nil), valid...)
+		binary.BigEndian.PutUint32(data[1:avroHeaderSize], id)
+
+		return data
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "empty", data: nil, wantErr: ErrInvalidAvroMessage},
+		{name: "short header", data: valid[:3], wantErr: ErrInvalidAvroMessage},
+		{name: "wrong magic byte", data: append([]byte{1}, valid[1:]...), wantErr: ErrInvalidAvroMessage},
+		{name: "unknown schema", data: withSchemaID(99), wantErr: ErrSchemaNotFound},
+		{
+			name:    "event without payload",
+			data:    encodeAvro(t, avroEventSchemaID, avroOrderEvent{EventID: "evt", EventType: string(model.EventOrderCreated)}),
+			wantErr: ErrInvalidAvroMessage,
+		},
+		{name: "truncated body", data: valid[:len(valid)/2]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newTestAvro().Decode(tt.data)
+			if err == nil {
+				t.Fatal("Decode() succeeded")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
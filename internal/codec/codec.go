@@ -0,0 +1,90 @@
+// Package codec decodes messages of the orders topic in different wire formats into model.OrderEvent.
+package codec
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"strings"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// HeaderContentType is the Kafka header used to select the codec of a message.
+const HeaderContentType = "content-type"
+
+const ContentTypeJSON = "application/json"
+
+const (
+	NameJSON     = "json"
+	NameProtobuf = "protobuf"
+	NameAvro     = "avro"
+)
+
+var ErrUnknownCodec = errors.New("unknown codec")
+
+// Codec decodes a raw message into an event. Payload of the returned event is always JSON,
+// so events are handled the same way regardless of the wire format.
+type Codec interface {
+	Name() string
+	ContentTypes() []string
+	Decode(data []byte) (model.OrderEvent, error)
+}
+
+// Registry selects a codec by the content type of a message.
+type Registry struct {
+	byContentType map[string]Codec
+	defaultCodec  Codec
+}
+
+// NewRegistry creates a registry. Messages without a content type are decoded with the codec named defaultName.
+func NewRegistry(defaultName string, codecs ...Codec) (*Registry, error) {
+	r := &Registry{
+		byContentType: make(map[string]Codec),
+	}
+
+	for _, c := range codecs {
+		for _, ct := range c.ContentTypes() {
+			r.byContentType[ct] = c
+		}
+
+		if c.Name() == defaultName {
+			r.defaultCodec = c
+		}
+	}
+
+	if r.defaultCodec == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownCodec, defaultName)
+	}
+
+	return r, nil
+}
+
+// Lookup returns the codec for the content type, or the default codec if the content type is empty.
+func (r *Registry) Lookup(contentType string) (Codec, error) {
+	if contentType == "" {
+		return r.defaultCodec, nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid content type %q: %w", ErrUnknownCodec, contentType, err)
+	}
+
+	c, ok := r.byContentType[strings.ToLower(mediaType)]
+	if !ok {
+		return nil, fmt.Errorf("%w: content type %q", ErrUnknownCodec, contentType)
+	}
+
+	return c, nil
+}
+
+// derivedEventID returns an id for messages that have none (legacy bare orders),
+// so a redelivered message always gets the same id.
+func derivedEventID(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return "derived-" + hex.EncodeToString(sum[:16])
+}
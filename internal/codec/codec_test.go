@@ -0,0 +1,128 @@
+package codec
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// sampleOrder is the order of the task. The time has millisecond precision, as in the Avro schema.
+func sampleOrder() model.Order {
+	return model.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Entry:       "WBIL",
+		Delivery: model.Delivery{
+			Name:    "Test Testov",
+			Phone:   "+9720000000",
+			Zip:     "2639809",
+			City:    "Kiryat Mozkin",
+			Address: "Ploshad Mira 15",
+			Region:  "Kraiot",
+			Email:   "test@gmail.com",
+		},
+		Payment: model.Payment{
+			Transaction:  "b563feb7b2b84b6test",
+			Currency:     "USD",
+			Provider:     "wbpay",
+			Amount:       1817,
+			PaymentDt:    1637907727,
+			Bank:         "alpha",
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []model.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			RID:         "ab4219087a764ae0btest",
+			Name:        "Mascaras",
+			Sale:        30,
+			Size:        "0",
+			TotalPrice:  317,
+			NmID:        2389212,
+			Brand:       "Vivienne Sabo",
+			Status:      202,
+		}},
+		Locale:          "en",
+		CustomerID:      "test",
+		DeliveryService: "meest",
+		ShardKey:        "9",
+		SmID:            99,
+		DateCreated:     time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC),
+		OofShard:        "1",
+	}
+}
+
+// payloadAs decodes the JSON payload of an event into a value of type T.
+func payloadAs[T any](t *testing.T, event model.OrderEvent) T {
+	t.Helper()
+
+	var v T
+	if err := json.Unmarshal(event.Payload, &v); err != nil {
+		t.Fatalf("failed to unmarshal payload %s: %v", event.Payload, err)
+	}
+
+	return v
+}
+
+func assertPayload[T any](t *testing.T, event model.OrderEvent, want T) {
+	t.Helper()
+
+	if got := payloadAs[T](t, event); !reflect.DeepEqual(got, want) {
+		t.Errorf("payload = %+v, want %+v", got, want)
+	}
+}
+
+func TestRegistryLookup(t *testing.T) {
+	r, err := NewRegistry(NameJSON, NewJSON(), NewProtobuf(), NewAvro(nil))
+	if err != nil {
+		t.Fatalf("NewRegistry() error = %v", err)
+	}
+
+	tests := []struct {
+		contentType string
+		want        string
+		wantErr     bool
+	}{
+		{contentType: "", want: NameJSON},
+		{contentType: "application/json", want: NameJSON},
+		{contentType: "application/json; charset=utf-8", want: NameJSON},
+		{contentType: "Application/X-Protobuf", want: NameProtobuf},
+		{contentType: "application/protobuf", want: NameProtobuf},
+		{contentType: "avro/binary", want: NameAvro},
+		{contentType: "text/plain", wantErr: true},
+		{contentType: "application/", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			c, err := r.Lookup(tt.contentType)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnknownCodec) {
+					t.Errorf("Lookup() error = %v, want %v", err, ErrUnknownCodec)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Lookup() error = %v", err)
+			}
+
+			if c.Name() != tt.want {
+				t.Errorf("Lookup() = %s, want %s", c.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestNewRegistryUnknownDefault(t *testing.T) {
+	if _, err := NewRegistry(NameAvro, NewJSON()); !errors.Is(err, ErrUnknownCodec) {
+		t.Errorf("NewRegistry() error = %v, want %v", err, ErrUnknownCodec)
+	}
+}
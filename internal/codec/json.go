@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+type jsonCodec struct{}
+
+func NewJSON() Codec {
+	return jsonCodec{}
+}
+
+func (jsonCodec) Name() string {
+	return NameJSON
+}
+
+func (jsonCodec) ContentTypes() []string {
+	return []string{ContentTypeJSON}
+}
+
+// Decode accepts both the event envelope and a legacy bare order, which is treated as order.created.
+func (jsonCodec) Decode(data []byte) (model.OrderEvent, error) {
+	var event model.OrderEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return model.OrderEvent{}, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	if event.EventType == "" {
+		return model.OrderEvent{
+			EventID:    derivedEventID(data),
+			EventType:  model.EventOrderCreated,
+			OccurredAt: time.Now(),
+			Payload:    bytes.Clone(data),
+		}, nil
+	}
+
+	return event, nil
+}
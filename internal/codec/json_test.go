@@ -0,0 +1,84 @@
+package codec
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+func TestJSONDecodeEnvelope(t *testing.T) {
+	occurredAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	data, err := json.Marshal(map[string]any{
+		"event_id":    "evt-1",
+		"event_type":  model.EventOrderCancelled,
+		"occurred_at": occurredAt,
+		"payload":     model.OrderCancellation{OrderUID: "uid", Reason: "customer request"},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+
+	event, err := NewJSON().Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if event.EventID != "evt-1" || event.EventType != model.EventOrderCancelled || !event.OccurredAt.Equal(occurredAt) {
+		t.Errorf("Decode() = %+v, want evt-1 of type %s", event, model.EventOrderCancelled)
+	}
+
+	assertPayload(t, event, model.OrderCancellation{OrderUID: "uid", Reason: "customer request"})
+}
+
+func TestJSONDecodeLegacyOrder(t *testing.T) {
+	data, err := json.Marshal(sampleOrder())
+	if err != nil {
+		t.Fatalf("failed to marshal order: %v", err)
+	}
+
+	c := NewJSON()
+
+	event, err := c.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if event.EventType != model.EventOrderCreated {
+		t.Errorf("EventType = %s, want %s", event.EventType, model.EventOrderCreated)
+	}
+
+	if !strings.HasPrefix(event.EventID, "derived-") || event.OccurredAt.IsZero() {
+		t.Errorf("Decode() = %+v, want a derived id and the receive time", event)
+	}
+
+	assertPayload(t, event, sampleOrder())
+
+	// A redelivered message gets the same id, so it is deduplicated.
+	again, err := c.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if again.EventID != event.EventID {
+		t.Errorf("EventID of a redelivered message = %s, want %s", again.EventID, event.EventID)
+	}
+
+	// The payload does not share the memory of the message.
+	data[0] = ' '
+
+	if event.Payload[0] != '{' {
+		t.Error("payload shares the message buffer")
+	}
+}
+
+func TestJSONDecodeInvalid(t *testing.T) {
+	for _, data := range []string{"", "not json", `["order"]`, `{"event_type": 1}`} {
+		if _, err := NewJSON().Decode([]byte(data)); err == nil {
+			t.Errorf("Decode(%q) succeeded", data)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package codec
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"wb-tech-test-assignment/internal/model"
+	ordersv1 "wb-tech-test-assignment/pkg/api/orders/v1"
+)
+
+type protobufCodec struct{}
+
+func NewProtobuf() Codec {
+	return protobufCodec{}
+}
+
+func (protobufCodec) Name() string {
+	return NameProtobuf
+}
+
+func (protobufCodec) ContentTypes() []string {
+	return []string{"application/x-protobuf", "application/protobuf"}
+}
+
+// Decode decodes an orders.v1.OrderEvent message.
+func (protobufCodec) Decode(data []byte) (model.OrderEvent, error) {
+	var pb ordersv1.OrderEvent
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return model.OrderEvent{}, fmt.Errorf("failed to unmarshal protobuf event: %w", err)
+	}
+
+	var payload any
+
+	switch p := pb.GetPayload().(type) {
+	case *ordersv1.OrderEvent_Order:
+		payload = orderFromProto(p.Order)
+	case *ordersv1.OrderEvent_ItemStatusChange:
+		payload = model.ItemStatusChange{
+			OrderUID: p.ItemStatusChange.GetOrderUid(),
+			ChrtID:   int(p.ItemStatusChange.GetChrtId()),
+			Status:   int(p.ItemStatusChange.GetStatus()),
+		}
+	case *ordersv1.OrderEvent_Cancellation:
+		payload = model.OrderCancellation{
+			OrderUID: p.Cancellation.GetOrderUid(),
+			Reason:   p.Cancellation.GetReason(),
+		}
+	default:
+		return model.OrderEvent{}, fmt.Errorf("protobuf event %q has no payload", pb.GetEventId())
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return model.OrderEvent{}, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	return model.OrderEvent{
+		EventID:    pb.GetEventId(),
+		EventType:  model.EventType(pb.GetEventType()),
+		OccurredAt: pb.GetOccurredAt().AsTime(),
+		Payload:    data,
+	}, nil
+}
+
+func orderFromProto(pb *ordersv1.Order) model.Order {
+	order := model.Order{
+		OrderUID:    pb.GetOrderUid(),
+		TrackNumber: pb.GetTrackNumber(),
+		Entry:       pb.GetEntry(),
+		Delivery: model.Delivery{
+			Name:    pb.GetDelivery().GetName(),
+			Phone:   pb.GetDelivery().GetPhone(),
+			Zip:     pb.GetDelivery().GetZip(),
+			City:    pb.GetDelivery().GetCity(),
+			Address: pb.GetDelivery().GetAddress(),
+			Region:  pb.GetDelivery().GetRegion(),
+			Email:   pb.GetDelivery().GetEmail(),
+		},
+		Payment: model.Payment{
+			Transaction:  pb.GetPayment().GetTransaction(),
+			RequestID:    pb.GetPayment().GetRequestId(),
+			Currency:     pb.GetPayment().GetCurrency(),
+			Provider:     pb.GetPayment().GetProvider(),
+			Amount:       int(pb.GetPayment().GetAmount()),
+			PaymentDt:    pb.GetPayment().GetPaymentDt(),
+			Bank:         pb.GetPayment().GetBank(),
+			DeliveryCost: int(pb.GetPayment().GetDeliveryCost()),
+			GoodsTotal:   int(pb.GetPayment().GetGoodsTotal()),
+			CustomFee:    int(pb.GetPayment().GetCustomFee()),
+		},
+		Items:             make([]model.Item, 0, len(pb.GetItems())),
+		Locale:            pb.GetLocale(),
+		InternalSignature: pb.GetInternalSignature(),
+		CustomerID:        pb.GetCustomerId(),
+		DeliveryService:   pb.GetDeliveryService(),
+		ShardKey:          pb.GetShardkey(),
+		SmID:              int(pb.GetSmId()),
+		OofShard:          pb.GetOofShard(),
+	}
+
+	if pb.GetDateCreated() != nil {
+		order.DateCreated = pb.GetDateCreated().AsTime()
+	}
+
+	for _, item := range pb.GetItems() {
+		order.Items = append(order.Items, model.Item{
+			ChrtID:      int(item.GetChrtId()),
+			TrackNumber: item.GetTrackNumber(),
+			Price:       int(item.GetPrice()),
+			RID:         item.GetRid(),
+			Name:        item.GetName(),
+			Sale:        int(item.GetSale()),
+			Size:        item.GetSize(),
+			TotalPrice:  int(item.GetTotalPrice()),
+			NmID:        int(item.GetNmId()),
+			Brand:       item.GetBrand(),
+			Status:      int(item.GetStatus()),
+		})
+	}
+
+	return order
+}
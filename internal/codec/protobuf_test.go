@@ -0,0 +1,157 @@
+package codec
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"wb-tech-test-assignment/internal/model"
+	ordersv1 "wb-tech-test-assignment/pkg/api/orders/v1"
+)
+
+func sampleOrderProto() *ordersv1.Order {
+	o := sampleOrder()
+	item := o.Items[0]
+
+	return &ordersv1.Order{
+		OrderUid:    o.OrderUID,
+		TrackNumber: o.TrackNumber,
+		Entry:       o.Entry,
+		Delivery: &ordersv1.Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+		},
+		Payment: &ordersv1.Payment{
+			Transaction:  o.Payment.Transaction,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       int64(o.Payment.Amount),
+			PaymentDt:    o.Payment.PaymentDt,
+			Bank:         o.Payment.Bank,
+			DeliveryCost: int64(o.Payment.DeliveryCost),
+			GoodsTotal:   int64(o.Payment.GoodsTotal),
+		},
+		Items: []*ordersv1.Item{{
+			ChrtId:      int64(item.ChrtID),
+			TrackNumber: item.TrackNumber,
+			Price:       int64(item.Price),
+			Rid:         item.RID,
+			Name:        item.Name,
+			Sale:        int64(item.Sale),
+			Size:        item.Size,
+			TotalPrice:  int64(item.TotalPrice),
+			NmId:        int64(item.NmID),
+			Brand:       item.Brand,
+			Status:      int64(item.Status),
+		}},
+		Locale:          o.Locale,
+		CustomerId:      o.CustomerID,
+		DeliveryService: o.DeliveryService,
+		Shardkey:        o.ShardKey,
+		SmId:            int64(o.SmID),
+		DateCreated:     timestamppb.New(o.DateCreated),
+		OofShard:        o.OofShard,
+	}
+}
+
+func TestProtobufDecode(t *testing.T) {
+	occurredAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	// Fields maintained by the storage are not taken from a message.
+	withStoredFields := sampleOrderProto()
+	withStoredFields.UpdatedAt = timestamppb.New(occurredAt)
+	withStoredFields.Cancellation = &ordersv1.Cancellation{CancelledAt: timestamppb.New(occurredAt), Reason: "forged"}
+
+	tests := []struct {
+		name      string
+		eventType model.EventType
+		event     *ordersv1.OrderEvent
+		check     func(t *testing.T, event model.OrderEvent)
+	}{
+		{
+			name:      "order",
+			eventType: model.EventOrderCreated,
+			event:     &ordersv1.OrderEvent{Payload: &ordersv1.OrderEvent_Order{Order: sampleOrderProto()}},
+			check: func(t *testing.T, event model.OrderEvent) {
+				assertPayload(t, event, sampleOrder())
+			},
+		},
+		{
+			name:      "order with stored fields",
+			eventType: model.EventOrderUpdated,
+			event:     &ordersv1.OrderEvent{Payload: &ordersv1.OrderEvent_Order{Order: withStoredFields}},
+			check: func(t *testing.T, event model.OrderEvent) {
+				assertPayload(t, event, sampleOrder())
+			},
+		},
+		{
+			name:      "item status change",
+			eventType: model.EventOrderItemStatusChanged,
+			event: &ordersv1.OrderEvent{Payload: &ordersv1.OrderEvent_ItemStatusChange{
+				ItemStatusChange: &ordersv1.ItemStatusChange{OrderUid: "uid", ChrtId: 9934930, Status: 300},
+			}},
+			check: func(t *testing.T, event model.OrderEvent) {
+				assertPayload(t, event, model.ItemStatusChange{OrderUID: "uid", ChrtID: 9934930, Status: 300})
+			},
+		},
+		{
+			name:      "cancellation",
+			eventType: model.EventOrderCancelled,
+			event: &ordersv1.OrderEvent{Payload: &ordersv1.OrderEvent_Cancellation{
+				Cancellation: &ordersv1.OrderCancellation{OrderUid: "uid", Reason: "fraud"},
+			}},
+			check: func(t *testing.T, event model.OrderEvent) {
+				assertPayload(t, event, model.OrderCancellation{OrderUID: "uid", Reason: "fraud"})
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.EventId = "evt-" + tt.name
+			tt.event.EventType = string(tt.eventType)
+			tt.event.OccurredAt = timestamppb.New(occurredAt)
+
+			data, err := proto.Marshal(tt.event)
+			if err != nil {
+				t.Fatalf("failed to marshal event: %v", err)
+			}
+
+			event, err := NewProtobuf().Decode(data)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+
+			if event.EventID != tt.event.EventId || event.EventType != tt.eventType || !event.OccurredAt.Equal(occurredAt) {
+				t.Errorf("Decode() = %+v, want %s of type %s at %s", event, tt.event.EventId, tt.eventType, occurredAt)
+			}
+
+			tt.check(t, event)
+		})
+	}
+}
+
+func TestProtobufDecodeInvalid(t *testing.T) {
+	noPayload, err := proto.Marshal(&ordersv1.OrderEvent{EventId: "evt", EventType: string(model.EventOrderCreated)})
+	if err != nil {
+		t.Fatalf("failed to marshal event: %v", err)
+	}
+
+	for name, data := range map[string][]byte{
+		"no payload": noPayload,
+		"garbage":    []byte{0xff, 0xff, 0xff},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewProtobuf().Decode(data); err == nil {
+				t.Error("Decode() succeeded")
+			}
+		})
+	}
+}
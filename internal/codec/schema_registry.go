@@ -0,0 +1,64 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+)
+
+var ErrSchemaNotFound = errors.New("schema not found")
+
+// SchemaRegistry resolves Avro schema ids found in the message header.
+type SchemaRegistry interface {
+	Schema(id int) (avro.Schema, error)
+}
+
+// FileSchemaRegistry is a local stand-in for a schema registry: the schema with id N
+// is read from the file "<dir>/N.avsc". Parsed schemas are cached.
+type FileSchemaRegistry struct {
+	dir     string
+	mu      sync.RWMutex
+	schemas map[int]avro.Schema
+}
+
+func NewFileSchemaRegistry(dir string) *FileSchemaRegistry {
+	return &FileSchemaRegistry{
+		dir:     dir,
+		schemas: make(map[int]avro.Schema),
+	}
+}
+
+func (r *FileSchemaRegistry) Schema(id int) (avro.Schema, error) {
+	r.mu.RLock()
+	schema, ok := r.schemas[id]
+	r.mu.RUnlock()
+
+	if ok {
+		return schema, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(r.dir, strconv.Itoa(id)+".avsc"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("%w: id %d", ErrSchemaNotFound, id)
+		}
+
+		return nil, fmt.Errorf("failed to read schema %d: %w", id, err)
+	}
+
+	schema, err = avro.ParseBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %d: %w", id, err)
+	}
+
+	r.mu.Lock()
+	r.schemas[id] = schema
+	r.mu.Unlock()
+
+	return schema, nil
+}
@@ -14,12 +14,15 @@ import (
 var ErrConfigPathIsEmpty = errors.New("config path is empty")
 
 type Config struct {
-	App        `yaml:"app"`
-	Logger     `yaml:"log"`
-	Database   `yaml:"database"`
-	Redis      `yaml:"redis"`
-	Kafka      `yaml:"kafka"`
-	HTTPServer `yaml:"http_server"`
+	App           `yaml:"app"`
+	Logger        `yaml:"log"`
+	Database      `yaml:"database"`
+	Redis         `yaml:"redis"`
+	Kafka         `yaml:"kafka"`
+	HTTPServer    `yaml:"http_server"`
+	GRPCServer    `yaml:"grpc_server"`
+	MetricsServer `yaml:"metrics_server"`
+	Validation    `yaml:"validation"`
 }
 
 type App struct {
@@ -40,15 +43,17 @@ type Rotation struct {
 }
 
 type Database struct {
-	Host      string    `yaml:"host"`
-	Port      uint16    `yaml:"port"`
-	User      string    `yaml:"user"`
-	Password  string    `yaml:"password"`
-	Name      string    `yaml:"name"`
-	SSLMode   string    `yaml:"ssl_mode"`
-	MaxConns  int32     `yaml:"max_conns"`
-	MinConns  int32     `yaml:"min_conns"`
-	Migration Migration `yaml:"migration"`
+	Host     string `yaml:"host"`
+	Port     uint16 `yaml:"port"`
+	User     string `yaml:"user"`
+	Password string `yaml:"password"`
+	Name     string `yaml:"name"`
+	SSLMode  string `yaml:"ssl_mode"`
+	MaxConns int32  `yaml:"max_conns"`
+	MinConns int32  `yaml:"min_conns"`
+	// ConflictPolicy is "replace" (default) or "reject", see repository.ConflictPolicy.
+	ConflictPolicy string    `yaml:"conflict_policy"`
+	Migration      Migration `yaml:"migration"`
 }
 
 type Migration struct {
@@ -74,12 +79,39 @@ type Subscriber struct {
 	Name             string           `yaml:"name"`
 	WorkerCount      int              `yaml:"worker_count"`
 	OrdersSubscriber OrdersSubscriber `yaml:"orders_subscriber"`
+	DeadLetter       DeadLetter       `yaml:"dead_letter"`
+	Retry            Retry            `yaml:"retry"`
+	Batch            Batch            `yaml:"batch"`
+	SchemaRegistry   SchemaRegistry   `yaml:"schema_registry"`
 }
 
 type OrdersSubscriber struct {
 	BufferSize int    `yaml:"buffer_size"`
 	Topic      string `yaml:"topic"`
 	GroupID    string `yaml:"group_id"`
+	Codec      string `yaml:"codec"`
+}
+
+type DeadLetter struct {
+	Enable bool   `yaml:"enable"`
+	Topic  string `yaml:"topic"`
+}
+
+type Retry struct {
+	// MaxAttempts includes the first attempt, 5 if zero; 1 disables retries.
+	MaxAttempts    int           `yaml:"max_attempts"`
+	InitialBackoff time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     time.Duration `yaml:"max_backoff"`
+}
+
+type Batch struct {
+	Enable  bool          `yaml:"enable"`
+	MaxSize int           `yaml:"max_size"`
+	MaxWait time.Duration `yaml:"max_wait"`
+}
+
+type SchemaRegistry struct {
+	Path string `yaml:"path"`
 }
 
 type Producer struct {
@@ -93,19 +125,133 @@ type OrdersProducer struct {
 }
 
 type HTTPServer struct {
-	Host     string  `yaml:"host"`
-	Port     uint16  `yaml:"port"`
-	BasePath string  `yaml:"base_path"`
-	Timeout  Timeout `yaml:"timeout"`
+	Host string `yaml:"host"`
+	Port uint16 `yaml:"port"`
+	// BasePath is the prefix of all API routes, for example /api.
+	BasePath  string      `yaml:"base_path"`
+	Versions  APIVersions `yaml:"versions"`
+	Timeout   Timeout     `yaml:"timeout"`
+	Admin     Admin       `yaml:"admin"`
+	Auth      Auth        `yaml:"auth"`
+	RateLimit RateLimit   `yaml:"rate_limit"`
+	Ingestion Ingestion   `yaml:"ingestion"`
+	Lookup    Lookup      `yaml:"lookup"`
+	OpenAPI   OpenAPI     `yaml:"openapi"`
+	Stream    Stream      `yaml:"stream"`
+
+	// OrderCacheControl is the Cache-Control header of order responses.
+	OrderCacheControl string `yaml:"order_cache_control"`
+}
+
+type APIVersions struct {
+	// Unversioned mounts the v1 routes without the version prefix as well, for clients of the API before versioning.
+	Unversioned APIVersion `yaml:"unversioned"`
+	V1          APIVersion `yaml:"v1"`
+	V2          APIVersion `yaml:"v2"`
+}
+
+type APIVersion struct {
+	Enable bool `yaml:"enable"`
+	// Deprecation and Sunset are announced in the Deprecation and Sunset headers of every response of the version if set.
+	Deprecation time.Time `yaml:"deprecation"`
+	Sunset      time.Time `yaml:"sunset"`
+}
+
+type Admin struct {
+	// TokenSHA256 is a hex encoded SHA-256 of the admin bearer token. It is an API key with the admin role.
+	// If there is neither the token nor another way to get the admin role, the admin API is not mounted.
+	TokenSHA256 string `yaml:"token_sha256" env:"HTTP_ADMIN_TOKEN_SHA256"`
+}
+
+type Auth struct {
+	// Enable requires credentials for the public API. If disabled, clients without credentials get
+	// the reader role and no personal data. The admin API always requires the admin role.
+	Enable  bool     `yaml:"enable"`
+	APIKeys []APIKey `yaml:"api_keys"`
+	// APIKeysFile is a YAML file with a list of keys in the format of api_keys, read at start.
+	// Keys should be kept there, in a secret mounted into the container, rather than in the config.
+	APIKeysFile string `yaml:"api_keys_file" env:"HTTP_AUTH_API_KEYS_FILE"`
+	JWT         JWT    `yaml:"jwt"`
+}
+
+type APIKey struct {
+	Name string `yaml:"name"`
+	// KeySHA256 is a hex encoded SHA-256 of the key.
+	KeySHA256 string   `yaml:"key_sha256"`
+	Roles     []string `yaml:"roles"`
+}
+
+type JWT struct {
+	// JWKSFile is a JSON Web Key Set with the public keys of the issuer, JWT is disabled if empty.
+	JWKSFile string `yaml:"jwks_file"`
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// RolesClaim is the claim with the roles, "roles" if empty.
+	RolesClaim string        `yaml:"roles_claim"`
+	Leeway     time.Duration `yaml:"leeway"`
+}
+
+type RateLimit struct {
+	// Rate is the number of requests per second of a client (API key, token subject or IP address), unlimited if zero.
+	Rate float64 `yaml:"rate"`
+	// Burst is the size of the token bucket, one second of the rate if zero.
+	Burst int `yaml:"burst"`
+	// IPRate and IPBurst limit requests of an IP address before authentication, including the ones with
+	// invalid credentials. Rate and Burst if zero; raise them if many clients share an address.
+	IPRate  float64 `yaml:"ip_rate"`
+	IPBurst int     `yaml:"ip_burst"`
+	// Redis keeps the buckets in Redis, so the limits are shared by all instances of the service.
+	Redis bool `yaml:"redis"`
+	// MaxInFlight is the number of API requests handled at once by the instance, unlimited if zero. Event streams are not counted.
+	MaxInFlight int `yaml:"max_in_flight"`
+}
+
+type Ingestion struct {
+	// Mode is publish (to kafka.producer.orders_producer.topic) or persist (directly to the database).
+	Mode         string `yaml:"mode"`
+	MaxBatchSize int    `yaml:"max_batch_size"`
+}
+
+type Lookup struct {
+	MaxOrderUIDs int `yaml:"max_order_uids"`
+}
+
+type OpenAPI struct {
+	ValidateRequests bool `yaml:"validate_requests"`
+	// ValidateResponses logs responses that do not match the specification, for development only.
+	ValidateResponses bool `yaml:"validate_responses"`
+}
+
+type Stream struct {
+	Heartbeat time.Duration `yaml:"heartbeat"`
 }
 
 type Timeout struct {
+	// Request is the deadline of API requests, except the export and the event stream. Expired requests get 504.
 	Request time.Duration `yaml:"request"`
 	Read    time.Duration `yaml:"read"`
 	Write   time.Duration `yaml:"write"`
 	Idle    time.Duration `yaml:"idle"`
 }
 
+type GRPCServer struct {
+	Enable     bool   `yaml:"enable"`
+	Host       string `yaml:"host"`
+	Port       uint16 `yaml:"port"`
+	Reflection bool   `yaml:"reflection"`
+}
+
+// MetricsServer serves /metrics on its own port, which is not exposed with the public API.
+type MetricsServer struct {
+	Enable bool   `yaml:"enable"`
+	Host   string `yaml:"host"`
+	Port   uint16 `yaml:"port"`
+}
+
+type Validation struct {
+	Rules map[string]bool `yaml:"rules"`
+}
+
 func MustLoadConfig() *Config {
 	cfg, err := LoadConfig()
 	if err != nil {
@@ -131,9 +277,24 @@ func LoadConfig() (*Config, error) {
 		panic("failed to read config: " + err.Error())
 	}
 
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
 	return &config, nil
 }
 
+// validate rejects values that would otherwise silently fall back to a default.
+func (c *Config) validate() error {
+	switch c.Database.ConflictPolicy {
+	case "", "replace", "reject":
+	default:
+		return fmt.Errorf("unknown database.conflict_policy %q, expected \"replace\" or \"reject\"", c.Database.ConflictPolicy)
+	}
+
+	return nil
+}
+
 func MustPrintConfig(cfg *Config) {
 	if err := PrintConfig(cfg); err != nil {
 		panic(err)
@@ -0,0 +1,88 @@
+// Package correlation carries the correlation ID of an HTTP request or a Kafka message through
+// the context into logs and the headers of produced messages.
+package correlation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderHTTP is accepted from clients and echoed in responses.
+	HeaderHTTP = "X-Request-ID"
+
+	// HeaderKafka is read from consumed messages and attached to produced ones.
+	HeaderKafka = "correlation-id"
+
+	// LogField is the field of log lines with the ID.
+	LogField = "correlation_id"
+
+	maxIDLength = 128
+)
+
+type contextKey struct{}
+
+// NewID returns a random 128-bit ID in hex.
+func NewID() string {
+	var b [16]byte
+
+	_, _ = rand.Read(b[:])
+
+	return hex.EncodeToString(b[:])
+}
+
+// Valid reports whether an ID received from outside can be used as is: it is not empty, not longer
+// than 128 characters and has only printable ASCII characters without spaces.
+func Valid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// WithID returns a copy of ctx carrying the ID.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// ID returns the ID carried by ctx, empty if there is none.
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+
+	return id
+}
+
+// Logger returns log with the ID carried by ctx, log itself if there is none.
+func Logger(ctx context.Context, log *zap.Logger) *zap.Logger {
+	if id := ID(ctx); id != "" {
+		return log.With(zap.String(LogField, id))
+	}
+
+	return log
+}
+
+// Headers adds the ID carried by ctx to the headers of a produced message, headers may be nil.
+func Headers(ctx context.Context, headers map[string]string) map[string]string {
+	id := ID(ctx)
+	if id == "" {
+		return headers
+	}
+
+	if headers == nil {
+		headers = make(map[string]string, 1)
+	}
+
+	headers[HeaderKafka] = id
+
+	return headers
+}
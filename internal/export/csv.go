@@ -0,0 +1,53 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+type csvWriter struct {
+	w      *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{
+		w:      csv.NewWriter(w),
+		record: make([]string, len(columns)),
+	}
+
+	if err := cw.w.Write(columns); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	return cw, nil
+}
+
+func (c *csvWriter) WriteOrder(order model.Order) error {
+	for _, row := range rows(order) {
+		for i, v := range row {
+			if v == nil {
+				c.record[i] = ""
+			} else {
+				c.record[i] = fmt.Sprint(v)
+			}
+		}
+
+		if err := c.w.Write(c.record); err != nil {
+			return fmt.Errorf("failed to write order %s: %w", order.OrderUID, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+func (c *csvWriter) Abort() {}
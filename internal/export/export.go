@@ -0,0 +1,181 @@
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+var ErrUnknownFormat = errors.New("unknown export format")
+
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	FormatXLSX   Format = "xlsx"
+)
+
+// ContentType returns the media type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Writer writes orders one by one. Close completes the output after the last order, Abort releases
+// the resources of a failed export without writing anything else. One of them must be called.
+type Writer interface {
+	WriteOrder(order model.Order) error
+	Close() error
+	Abort()
+}
+
+// NewWriter creates a writer of the format.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatNDJSON:
+		return newNDJSONWriter(w), nil
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+}
+
+// columns of the tabular formats, an order is flattened to one row per item.
+var columns = []string{
+	"order_uid",
+	"track_number",
+	"entry",
+	"locale",
+	"internal_signature",
+	"customer_id",
+	"delivery_service",
+	"shardkey",
+	"sm_id",
+	"date_created",
+	"oof_shard",
+	"updated_at",
+	"cancelled_at",
+	"cancel_reason",
+	"delivery_name",
+	"delivery_phone",
+	"delivery_zip",
+	"delivery_city",
+	"delivery_address",
+	"delivery_region",
+	"delivery_email",
+	"payment_transaction",
+	"payment_request_id",
+	"payment_currency",
+	"payment_provider",
+	"payment_amount",
+	"payment_dt",
+	"payment_bank",
+	"payment_delivery_cost",
+	"payment_goods_total",
+	"payment_custom_fee",
+	"item_chrt_id",
+	"item_track_number",
+	"item_price",
+	"item_rid",
+	"item_name",
+	"item_sale",
+	"item_size",
+	"item_total_price",
+	"item_nm_id",
+	"item_brand",
+	"item_status",
+}
+
+// rows flattens the order, an order without items gives one row with empty item columns.
+func rows(order model.Order) [][]any {
+	var cancelledAt, cancelReason any
+	if order.Cancellation != nil {
+		cancelledAt = formatTime(order.Cancellation.CancelledAt)
+		cancelReason = order.Cancellation.Reason
+	}
+
+	head := []any{
+		order.OrderUID,
+		order.TrackNumber,
+		order.Entry,
+		order.Locale,
+		order.InternalSignature,
+		order.CustomerID,
+		order.DeliveryService,
+		order.ShardKey,
+		order.SmID,
+		formatTime(order.DateCreated),
+		order.OofShard,
+		formatTime(order.UpdatedAt),
+		cancelledAt,
+		cancelReason,
+		order.Delivery.Name,
+		order.Delivery.Phone,
+		order.Delivery.Zip,
+		order.Delivery.City,
+		order.Delivery.Address,
+		order.Delivery.Region,
+		order.Delivery.Email,
+		order.Payment.Transaction,
+		order.Payment.RequestID,
+		order.Payment.Currency,
+		order.Payment.Provider,
+		order.Payment.Amount,
+		order.Payment.PaymentDt,
+		order.Payment.Bank,
+		order.Payment.DeliveryCost,
+		order.Payment.GoodsTotal,
+		order.Payment.CustomFee,
+	}
+
+	if len(order.Items) == 0 {
+		return [][]any{append(head, make([]any, len(columns)-len(head))...)}
+	}
+
+	result := make([][]any, 0, len(order.Items))
+
+	for _, item := range order.Items {
+		row := make([]any, 0, len(columns))
+		row = append(row, head...)
+		row = append(row,
+			item.ChrtID,
+			item.TrackNumber,
+			item.Price,
+			item.RID,
+			item.Name,
+			item.Sale,
+			item.Size,
+			item.TotalPrice,
+			item.NmID,
+			item.Brand,
+			item.Status,
+		)
+
+		result = append(result, row)
+	}
+
+	return result
+}
+
+func formatTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,32 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// ndjsonWriter writes an order per line in the same JSON as the orders API.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func newNDJSONWriter(w io.Writer) *ndjsonWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) WriteOrder(order model.Order) error {
+	if err := n.enc.Encode(order); err != nil {
+		return fmt.Errorf("failed to write order %s: %w", order.OrderUID, err)
+	}
+
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+func (n *ndjsonWriter) Abort() {}
@@ -0,0 +1,98 @@
+package export
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/xuri/excelize/v2"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+const xlsxSheet = "Orders"
+
+// xlsxWriter writes rows with the excelize stream writer, which keeps large sheets in a temporary
+// file. The workbook is a zip archive, so it is written to w only on Close.
+type xlsxWriter struct {
+	w    io.Writer
+	file *excelize.File
+	sw   *excelize.StreamWriter
+	row  int
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+
+	if err := file.SetSheetName("Sheet1", xlsxSheet); err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to rename sheet: %w", err)
+	}
+
+	sw, err := file.NewStreamWriter(xlsxSheet)
+	if err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to create stream writer: %w", err)
+	}
+
+	x := &xlsxWriter{
+		w:    w,
+		file: file,
+		sw:   sw,
+	}
+
+	header := make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+
+	if err = x.writeRow(header); err != nil {
+		_ = file.Close()
+
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	return x, nil
+}
+
+func (x *xlsxWriter) WriteOrder(order model.Order) error {
+	for _, row := range rows(order) {
+		if err := x.writeRow(row); err != nil {
+			return fmt.Errorf("failed to write order %s: %w", order.OrderUID, err)
+		}
+	}
+
+	return nil
+}
+
+func (x *xlsxWriter) Close() error {
+	defer func() {
+		_ = x.file.Close()
+	}()
+
+	if err := x.sw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush sheet: %w", err)
+	}
+
+	if err := x.file.Write(x.w); err != nil {
+		return fmt.Errorf("failed to write workbook: %w", err)
+	}
+
+	return nil
+}
+
+func (x *xlsxWriter) Abort() {
+	_ = x.file.Close()
+}
+
+func (x *xlsxWriter) writeRow(values []any) error {
+	x.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+
+	return x.sw.SetRow(cell, values)
+}
@@ -0,0 +1,108 @@
+// Package metrics exposes service metrics in the Prometheus format.
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"wb-tech-test-assignment/pkg/kafka"
+)
+
+const namespace = "wb_tech_test_assignment"
+
+type ConsumerStatsProvider interface {
+	Stats() kafka.Stats
+}
+
+// kafkaConsumerCollector reads consumer stats on every scrape.
+type kafkaConsumerCollector struct {
+	consumer ConsumerStatsProvider
+
+	lag               *prometheus.Desc
+	highWaterMark     *prometheus.Desc
+	markedOffset      *prometheus.Desc
+	partitionInFlight *prometheus.Desc
+	inFlight          *prometheus.Desc
+	received          *prometheus.Desc
+	processed         *prometheus.Desc
+	throughput        *prometheus.Desc
+}
+
+func NewKafkaConsumerCollector(consumer ConsumerStatsProvider) prometheus.Collector {
+	partitionLabels := []string{"group", "topic", "partition"}
+	groupLabels := []string{"group"}
+
+	return &kafkaConsumerCollector{
+		consumer: consumer,
+		lag: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "partition_lag"),
+			"Number of messages between the high water mark and the marked offset.",
+			partitionLabels, nil,
+		),
+		highWaterMark: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "partition_high_water_mark"),
+			"Offset of the next message that will be produced to the partition.",
+			partitionLabels, nil,
+		),
+		markedOffset: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "partition_marked_offset"),
+			"Next offset to be committed for the partition.",
+			partitionLabels, nil,
+		),
+		partitionInFlight: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "partition_in_flight_messages"),
+			"Messages of the partition received but not processed yet.",
+			partitionLabels, nil,
+		),
+		inFlight: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "in_flight_messages"),
+			"Messages received but not processed yet.",
+			groupLabels, nil,
+		),
+		received: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "messages_received_total"),
+			"Messages received from kafka.",
+			groupLabels, nil,
+		),
+		processed: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "messages_processed_total"),
+			"Messages processed and marked.",
+			groupLabels, nil,
+		),
+		throughput: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "kafka_consumer", "messages_per_second"),
+			"Processed messages per second averaged over the last seconds.",
+			groupLabels, nil,
+		),
+	}
+}
+
+func (c *kafkaConsumerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.lag
+	ch <- c.highWaterMark
+	ch <- c.markedOffset
+	ch <- c.partitionInFlight
+	ch <- c.inFlight
+	ch <- c.received
+	ch <- c.processed
+	ch <- c.throughput
+}
+
+func (c *kafkaConsumerCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.consumer.Stats()
+
+	for _, p := range stats.Partitions {
+		labels := []string{stats.GroupID, p.Topic, strconv.FormatInt(int64(p.Partition), 10)}
+
+		ch <- prometheus.MustNewConstMetric(c.lag, prometheus.GaugeValue, float64(p.Lag), labels...)
+		ch <- prometheus.MustNewConstMetric(c.highWaterMark, prometheus.GaugeValue, float64(p.HighWaterMark), labels...)
+		ch <- prometheus.MustNewConstMetric(c.markedOffset, prometheus.GaugeValue, float64(p.MarkedOffset), labels...)
+		ch <- prometheus.MustNewConstMetric(c.partitionInFlight, prometheus.GaugeValue, float64(p.InFlight), labels...)
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.inFlight, prometheus.GaugeValue, float64(stats.InFlight), stats.GroupID)
+	ch <- prometheus.MustNewConstMetric(c.received, prometheus.CounterValue, float64(stats.MessagesReceived), stats.GroupID)
+	ch <- prometheus.MustNewConstMetric(c.processed, prometheus.CounterValue, float64(stats.MessagesProcessed), stats.GroupID)
+	ch <- prometheus.MustNewConstMetric(c.throughput, prometheus.GaugeValue, stats.MessagesPerSecond, stats.GroupID)
+}
@@ -0,0 +1,54 @@
+package model
+
+import (
+	"time"
+)
+
+// ErasedValue replaces personal data of erased orders.
+const ErasedValue = "[erased]"
+
+// RedactedValue replaces personal data in responses to clients not allowed to see it.
+const RedactedValue = "[redacted]"
+
+type ErasureSubject string
+
+const (
+	ErasureSubjectCustomer ErasureSubject = "customer"
+	ErasureSubjectOrder    ErasureSubject = "order"
+)
+
+// ErasureRequest asks to erase personal data of all orders of a customer or of a single order.
+// RequestedBy is the client that triggered the erasure, OnBehalfOf is a note of that client about
+// the operator or the person it acts for.
+type ErasureRequest struct {
+	Subject     ErasureSubject `json:"subject"`
+	SubjectID   string         `json:"subject_id"`
+	RequestedBy string         `json:"requested_by"`
+	OnBehalfOf  string         `json:"on_behalf_of,omitempty"`
+	Reason      string         `json:"reason,omitempty"`
+}
+
+// ErasureResult is the audit entry of a processed request. Erased lists orders anonymised by this
+// request, Skipped lists matching orders that had already been erased.
+type ErasureResult struct {
+	AuditID   int64          `json:"audit_id"`
+	Subject   ErasureSubject `json:"subject"`
+	SubjectID string         `json:"subject_id"`
+	Erased    []string       `json:"erased"`
+	Skipped   []string       `json:"skipped"`
+	ErasedAt  time.Time      `json:"erased_at"`
+}
+
+// OrderUIDs returns all orders matching the request.
+func (r ErasureResult) OrderUIDs() []string {
+	return append(append(make([]string, 0, len(r.Erased)+len(r.Skipped)), r.Erased...), r.Skipped...)
+}
+
+// RedactPersonalData replaces the personal data of the customer, the same fields that are erased, with RedactedValue.
+func (o *Order) RedactPersonalData() {
+	o.Delivery.Name = RedactedValue
+	o.Delivery.Phone = RedactedValue
+	o.Delivery.Zip = RedactedValue
+	o.Delivery.Address = RedactedValue
+	o.Delivery.Email = RedactedValue
+}
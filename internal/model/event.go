@@ -0,0 +1,35 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventOrderCreated           EventType = "order.created"
+	EventOrderUpdated           EventType = "order.updated"
+	EventOrderItemStatusChanged EventType = "order.item_status_changed"
+	EventOrderCancelled         EventType = "order.cancelled"
+)
+
+// OrderEvent is the envelope of messages on the orders topic. Payload depends on EventType:
+// Order for created and updated events, ItemStatusChange and OrderCancellation for the others.
+type OrderEvent struct {
+	EventID    string          `json:"event_id"    validate:"required"`
+	EventType  EventType       `json:"event_type"  validate:"required,oneof=order.created order.updated order.item_status_changed order.cancelled"`
+	OccurredAt time.Time       `json:"occurred_at" validate:"required"`
+	Payload    json.RawMessage `json:"payload"     validate:"required"`
+}
+
+type ItemStatusChange struct {
+	OrderUID string `json:"order_uid" validate:"required"`
+	ChrtID   int    `json:"chrt_id"   validate:"required,gt=0"`
+	Status   int    `json:"status"    validate:"required,gt=0"`
+}
+
+type OrderCancellation struct {
+	OrderUID string `json:"order_uid" validate:"required"`
+	Reason   string `json:"reason"`
+}
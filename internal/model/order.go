@@ -19,6 +19,24 @@ type Order struct {
 	SmID              int       `json:"sm_id"             validate:"required"`
 	DateCreated       time.Time `json:"date_created"      validate:"required"`
 	OofShard          string    `json:"oof_shard"         validate:"required,numeric"`
+
+	// Cancellation is set by the order.cancelled event and is not part of the ingested order content.
+	Cancellation *Cancellation `json:"cancellation,omitempty"`
+
+	// UpdatedAt is set by the storage on every change of the order, a value from a message is ignored.
+	UpdatedAt time.Time `json:"updated_at,omitzero"`
+}
+
+// ClearStoredFields drops the fields maintained by the storage from an order received in a message,
+// so that a producer cannot set them.
+func (o *Order) ClearStoredFields() {
+	o.Cancellation = nil
+	o.UpdatedAt = time.Time{}
+}
+
+type Cancellation struct {
+	CancelledAt time.Time `json:"cancelled_at"`
+	Reason      string    `json:"reason,omitempty"`
 }
 
 type Delivery struct {
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+)
+
+type SortOrder string
+
+const (
+	SortDesc SortOrder = "desc"
+	SortAsc  SortOrder = "asc"
+)
+
+// OrderFilter selects orders for listing. Empty fields are not applied.
+type OrderFilter struct {
+	CustomerID      string
+	DeliveryService string
+	Locale          string
+	Currency        string
+	Bank            string
+	Brand           string
+
+	// CreatedFrom is inclusive, CreatedTo is exclusive.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+
+	// Orders are sorted by date_created, then by order_uid.
+	Sort SortOrder
+
+	// Cursor is an opaque position returned as OrderPage.NextCursor.
+	Cursor string
+	Limit  int
+}
+
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,7 @@
+package model
+
+// OrderLookup is the result of a batch lookup, Missing lists the requested order_uids that are not stored.
+type OrderLookup struct {
+	Orders  []Order  `json:"orders"`
+	Missing []string `json:"missing"`
+}
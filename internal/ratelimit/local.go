@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Local keeps buckets in memory, limits are per instance of the service. Full buckets are
+// dropped periodically, so the number of buckets depends on the number of active clients.
+type Local struct {
+	rate  float64
+	burst int
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLocal creates buckets of burst tokens refilled at rate tokens per second.
+func NewLocal(rate float64, burst int) *Local {
+	return &Local{
+		rate:      rate,
+		burst:     burst,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *Local) Allow(_ context.Context, key string) (Result, error) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.burst), updated: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = l.refill(b, now)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return result(allowed, b.tokens, l.rate, l.burst), nil
+}
+
+func (l *Local) refill(b *bucket, now time.Time) float64 {
+	return math.Min(float64(l.burst), b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+}
+
+func (l *Local) sweep(now time.Time) {
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.burst) {
+			delete(l.buckets, key)
+		}
+	}
+
+	l.lastSweep = now
+}
@@ -0,0 +1,149 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed bool
+		tokens  float64
+		rate    float64
+		burst   int
+		want    Result
+	}{
+		{
+			name:    "full after the request",
+			allowed: true,
+			tokens:  9,
+			rate:    1,
+			burst:   10,
+			want:    Result{Allowed: true, Limit: 10, Remaining: 9, Reset: time.Second},
+		},
+		{
+			name:    "fractional tokens are rounded down",
+			allowed: true,
+			tokens:  2.5,
+			rate:    2,
+			burst:   5,
+			want:    Result{Allowed: true, Limit: 5, Remaining: 2, Reset: 1250 * time.Millisecond},
+		},
+		{
+			name:    "denied",
+			allowed: false,
+			tokens:  0.25,
+			rate:    0.5,
+			burst:   2,
+			want:    Result{Limit: 2, RetryAfter: 1500 * time.Millisecond, Reset: 3500 * time.Millisecond},
+		},
+		{
+			name:    "denied when empty",
+			allowed: false,
+			tokens:  0,
+			rate:    10,
+			burst:   1,
+			want:    Result{Limit: 1, RetryAfter: 100 * time.Millisecond, Reset: 100 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result(tt.allowed, tt.tokens, tt.rate, tt.burst); got != tt.want {
+				t.Errorf("result() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalRefill(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		tokens  float64
+		elapsed time.Duration
+		want    float64
+	}{
+		{name: "no time passed", tokens: 1, want: 1},
+		{name: "partial refill", tokens: 1, elapsed: 1500 * time.Millisecond, want: 4},
+		{name: "capped at burst", tokens: 4, elapsed: time.Hour, want: 5},
+		{name: "from empty", elapsed: 500 * time.Millisecond, want: 1},
+	}
+
+	l := NewLocal(2, 5)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := &bucket{tokens: tt.tokens, updated: now.Add(-tt.elapsed)}
+
+			if got := l.refill(b, now); got != tt.want {
+				t.Errorf("refill() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalSweep(t *testing.T) {
+	now := time.Now()
+
+	l := NewLocal(1, 10)
+	l.buckets = map[string]*bucket{
+		"full":     {tokens: 10, updated: now},
+		"refilled": {tokens: 5, updated: now.Add(-5 * time.Second)},
+		"active":   {tokens: 5, updated: now.Add(-time.Second)},
+		"empty":    {tokens: 0, updated: now},
+	}
+
+	l.sweep(now)
+
+	for _, key := range []string{"active", "empty"} {
+		if _, ok := l.buckets[key]; !ok {
+			t.Errorf("bucket %q was dropped", key)
+		}
+	}
+
+	for _, key := range []string{"full", "refilled"} {
+		if _, ok := l.buckets[key]; ok {
+			t.Errorf("bucket %q was kept", key)
+		}
+	}
+
+	if !l.lastSweep.Equal(now) {
+		t.Errorf("lastSweep = %v, want %v", l.lastSweep, now)
+	}
+}
+
+func TestLocalAllow(t *testing.T) {
+	ctx := context.Background()
+
+	// The rate is low enough for the bucket not to refill during the test.
+	l := NewLocal(0.001, 3)
+
+	for i := range 3 {
+		res, err := l.Allow(ctx, "client")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+
+		if !res.Allowed || res.Limit != 3 || res.Remaining != 2-i {
+			t.Errorf("request %d: Allow() = %+v, want allowed with %d remaining", i, res, 2-i)
+		}
+	}
+
+	res, err := l.Allow(ctx, "client")
+	if err != nil {
+		t.Fatalf("Allow() error = %v", err)
+	}
+
+	if res.Allowed || res.Remaining != 0 || res.RetryAfter <= 0 {
+		t.Errorf("Allow() over the burst = %+v, want denied with RetryAfter", res)
+	}
+
+	// Buckets are per key.
+	if res, _ = l.Allow(ctx, "other"); !res.Allowed {
+		t.Errorf("Allow() of another key = %+v, want allowed", res)
+	}
+}
@@ -0,0 +1,48 @@
+// Package ratelimit implements token buckets per client, in memory or shared through Redis.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Result is the state of the bucket after a request.
+type Result struct {
+	Allowed bool
+
+	// Limit is the size of the bucket, Remaining is the number of tokens left in it.
+	Limit     int
+	Remaining int
+
+	// RetryAfter is the time until the next token if the request is not allowed.
+	RetryAfter time.Duration
+
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+}
+
+// Limiter takes a token from the bucket of the key.
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// result builds the result from the tokens left in a bucket of burst tokens refilled at rate per second.
+func result(allowed bool, tokens, rate float64, burst int) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     burst,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     seconds((float64(burst) - tokens) / rate),
+	}
+
+	if !allowed {
+		res.RetryAfter = seconds((1 - tokens) / rate)
+	}
+
+	return res
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(math.Max(s, 0) * float64(time.Second))
+}
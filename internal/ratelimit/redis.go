@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const keyPrefix = "ratelimit:"
+
+// takeToken refills the bucket by the time passed since its last update and takes a token.
+// The time of the Redis server is used, so clocks of the replicas do not matter.
+// Returns whether the token was taken and the tokens left, as a string to keep the fraction.
+var takeToken = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'updated')
+local tokens = tonumber(state[1]) or burst
+local updated = tonumber(state[2]) or now
+
+tokens = math.min(burst, tokens + math.max(0, now - updated) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil((burst - tokens) / rate * 1000) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+// Redis keeps buckets in Redis, limits are shared by all instances of the service.
+// A bucket expires once it would be full again.
+type Redis struct {
+	rdb   redis.Scripter
+	rate  float64
+	burst int
+}
+
+// NewRedis creates buckets of burst tokens refilled at rate tokens per second.
+func NewRedis(rdb redis.Scripter, rate float64, burst int) *Redis {
+	return &Redis{
+		rdb:   rdb,
+		rate:  rate,
+		burst: burst,
+	}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string) (Result, error) {
+	vals, err := takeToken.Run(ctx, r.rdb, []string{keyPrefix + key},
+		strconv.FormatFloat(r.rate, 'f', -1, 64), r.burst).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to take token: %w", err)
+	}
+
+	if len(vals) != 2 {
+		return Result{}, fmt.Errorf("unexpected token bucket reply %v", vals)
+	}
+
+	allowed, _ := vals[0].(int64)
+
+	tokens, err := strconv.ParseFloat(fmt.Sprint(vals[1]), 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse tokens: %w", err)
+	}
+
+	return result(allowed == 1, math.Max(tokens, 0), r.rate, r.burst), nil
+}
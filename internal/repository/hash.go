@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// contentHash returns a hex encoded SHA-256 of the order content. Values are normalized
+// to what Postgres stores, so a hash of an order read back from the database matches
+// the hash of the message it was inserted from. State changed by later events (cancellation,
+// item statuses) does not update the stored hash, so a redelivered order is still recognised.
+func contentHash(order model.Order) (string, error) {
+	order.Cancellation = nil
+	order.UpdatedAt = time.Time{}
+	order.DateCreated = order.DateCreated.UTC().Truncate(time.Microsecond)
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal order: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -15,17 +16,45 @@ import (
 // Пояснение: Можно было бы сделать 2 запроса SELECT в бд, но т.к. полей в таблицах довольно много
 // решил разнести это по отдельным запросам.
 
+// ConflictPolicy defines what PutOrder does when an order with the same order_uid
+// but different content is already stored.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyReplace overwrites the stored order with the new version, unless the order
+	// was changed by other events since it was stored, then apperrors.ErrOrderConflict is returned.
+	ConflictPolicyReplace ConflictPolicy = "replace"
+
+	// ConflictPolicyReject keeps the stored order and returns apperrors.ErrOrderConflict.
+	ConflictPolicyReject ConflictPolicy = "reject"
+)
+
 type OrderRepository struct {
-	db *pgxpool.Pool
+	db             *pgxpool.Pool
+	conflictPolicy ConflictPolicy
 }
 
-func NewOrderRepository(db *pgxpool.Pool) *OrderRepository {
+// NewOrderRepository uses ConflictPolicyReplace if the policy is empty, other values are
+// validated with the config.
+func NewOrderRepository(db *pgxpool.Pool, conflictPolicy ConflictPolicy) *OrderRepository {
+	if conflictPolicy == "" {
+		conflictPolicy = ConflictPolicyReplace
+	}
+
 	return &OrderRepository{
-		db: db,
+		db:             db,
+		conflictPolicy: conflictPolicy,
 	}
 }
 
+// PutOrder stores the order. Storing an identical order again returns apperrors.ErrOrderAlreadyExists,
+// storing a different order with the same order_uid is resolved according to the conflict policy.
 func (o *OrderRepository) PutOrder(ctx context.Context, order model.Order) error {
+	hash, err := contentHash(order)
+	if err != nil {
+		return fmt.Errorf("failed to calculate content hash: %w", err)
+	}
+
 	tx, err := o.db.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -35,24 +64,55 @@ func (o *OrderRepository) PutOrder(ctx context.Context, order model.Order) error
 		_ = tx.Rollback(ctx)
 	}()
 
-	err = o.insertOrder(ctx, tx, order)
+	inserted, err := o.insertOrder(ctx, tx, order, hash)
 	if err != nil {
 		return fmt.Errorf("failed to insert order: %w", err)
 	}
 
-	err = o.insertDelivery(ctx, tx, order.OrderUID, order.Delivery)
+	if inserted {
+		err = o.insertOrderDetails(ctx, tx, order)
+	} else {
+		err = o.resolveConflict(ctx, tx, order, hash)
+	}
+
 	if err != nil {
-		return fmt.Errorf("failed to insert delivery: %w", err)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
 	}
 
-	err = o.insertPayment(ctx, tx, order.OrderUID, order.Payment)
+	return nil
+}
+
+// UpdateOrder replaces the content of an existing order. Cancellation state is kept.
+func (o *OrderRepository) UpdateOrder(ctx context.Context, order model.Order) error {
+	hash, err := contentHash(order)
 	if err != nil {
-		return fmt.Errorf("failed to insert payment: %w", err)
+		return fmt.Errorf("failed to calculate content hash: %w", err)
 	}
 
-	err = o.insertItems(ctx, tx, order.OrderUID, order.Items)
+	tx, err := o.db.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to insert items: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	storedHash, err := o.selectContentHash(ctx, tx, order.OrderUID)
+	if err != nil {
+		return fmt.Errorf("failed to select content hash: %w", err)
+	}
+
+	if storedHash == hash {
+		return nil
+	}
+
+	if err = o.replaceOrder(ctx, tx, order, hash); err != nil {
+		return err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
@@ -62,6 +122,139 @@ func (o *OrderRepository) PutOrder(ctx context.Context, order model.Order) error
 	return nil
 }
 
+func (o *OrderRepository) UpdateItemStatus(ctx context.Context, orderUID string, chrtID, status int) error {
+	const query = `
+		WITH item AS (
+			UPDATE items
+			SET status = $3
+			WHERE order_uid = $1 AND chrt_id = $2
+			RETURNING order_uid
+		)
+		UPDATE orders
+		SET updated_at = now()
+		WHERE order_uid IN (SELECT order_uid FROM item);
+	`
+
+	tag, err := o.db.Exec(ctx, query, orderUID, chrtID, status)
+	if err != nil {
+		return fmt.Errorf("failed to update item status: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("%w: order_uid %s, chrt_id %d", apperrors.ErrOrderItemNotFound, orderUID, chrtID)
+	}
+
+	return nil
+}
+
+// CancelOrder marks the order as cancelled. Cancelling an already cancelled order keeps the first cancellation.
+func (o *OrderRepository) CancelOrder(ctx context.Context, orderUID string, cancellation model.Cancellation) error {
+	const query = `
+		UPDATE orders
+		SET cancelled_at  = COALESCE(cancelled_at, $2),
+		    cancel_reason = CASE WHEN cancelled_at IS NULL THEN $3 ELSE cancel_reason END,
+		    updated_at    = CASE WHEN cancelled_at IS NULL THEN now() ELSE updated_at END
+		WHERE order_uid = $1;
+	`
+
+	tag, err := o.db.Exec(ctx, query, orderUID, cancellation.CancelledAt, cancellation.Reason)
+	if err != nil {
+		return fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return apperrors.ErrOrderNotFound
+	}
+
+	return nil
+}
+
+// resolveConflict compares the stored order with the new one and, if the policy allows it, replaces
+// the order row and removes its details, so they can be inserted again.
+func (o *OrderRepository) resolveConflict(ctx context.Context, tx pgx.Tx, order model.Order, hash string) error {
+	storedHash, err := o.selectContentHash(ctx, tx, order.OrderUID)
+	if err != nil {
+		// A redelivered message must not restore erased personal data.
+		if errors.Is(err, apperrors.ErrOrderErased) {
+			return apperrors.ErrOrderAlreadyExists
+		}
+
+		return fmt.Errorf("failed to select content hash: %w", err)
+	}
+
+	if storedHash == hash {
+		return apperrors.ErrOrderAlreadyExists
+	}
+
+	if o.conflictPolicy == ConflictPolicyReject {
+		return fmt.Errorf("%w: order_uid %s", apperrors.ErrOrderConflict, order.OrderUID)
+	}
+
+	// A redelivered or stale order.created must not undo order.updated, item status changes or the cancellation.
+	changed, err := o.selectChangedByEvents(ctx, tx, order.OrderUID)
+	if err != nil {
+		return fmt.Errorf("failed to select order state: %w", err)
+	}
+
+	if changed {
+		return fmt.Errorf("%w: order_uid %s was changed by later events", apperrors.ErrOrderConflict, order.OrderUID)
+	}
+
+	if err = o.replaceOrder(ctx, tx, order, hash); err != nil {
+		return err
+	}
+
+	const query = `UPDATE orders SET stored_at = now() WHERE order_uid = $1;`
+
+	if _, err = tx.Exec(ctx, query, order.OrderUID); err != nil {
+		return fmt.Errorf("failed to update stored_at: %w", err)
+	}
+
+	return nil
+}
+
+// selectChangedByEvents reports whether the order was changed after its content was stored by
+// order.created. Both columns are set to now() of the storing transaction.
+func (o *OrderRepository) selectChangedByEvents(ctx context.Context, ext RepoExtension, orderUID string) (bool, error) {
+	const query = `SELECT updated_at > stored_at FROM orders WHERE order_uid = $1;`
+
+	var changed bool
+	if err := ext.QueryRow(ctx, query, orderUID).Scan(&changed); err != nil {
+		return false, err
+	}
+
+	return changed, nil
+}
+
+// replaceOrder overwrites the order row and all its details.
+func (o *OrderRepository) replaceOrder(ctx context.Context, tx pgx.Tx, order model.Order, hash string) error {
+	if err := o.updateOrder(ctx, tx, order, hash); err != nil {
+		return fmt.Errorf("failed to update order: %w", err)
+	}
+
+	if err := o.deleteOrderDetails(ctx, tx, order.OrderUID); err != nil {
+		return fmt.Errorf("failed to delete order details: %w", err)
+	}
+
+	return o.insertOrderDetails(ctx, tx, order)
+}
+
+func (o *OrderRepository) insertOrderDetails(ctx context.Context, tx pgx.Tx, order model.Order) error {
+	if err := o.insertDelivery(ctx, tx, order.OrderUID, order.Delivery); err != nil {
+		return fmt.Errorf("failed to insert delivery: %w", err)
+	}
+
+	if err := o.insertPayment(ctx, tx, order.OrderUID, order.Payment); err != nil {
+		return fmt.Errorf("failed to insert payment: %w", err)
+	}
+
+	if err := o.insertItems(ctx, tx, order.OrderUID, order.Items); err != nil {
+		return fmt.Errorf("failed to insert items: %w", err)
+	}
+
+	return nil
+}
+
 func (o *OrderRepository) GetOrder(ctx context.Context, orderUID string) (model.Order, error) {
 	tx, err := o.db.Begin(ctx)
 	if err != nil {
@@ -140,7 +333,8 @@ func (o *OrderRepository) GetOrdersBatch(ctx context.Context, limit, offset int)
 	return orders, nil
 }
 
-func (o *OrderRepository) insertOrder(ctx context.Context, ext RepoExtension, order model.Order) error {
+// insertOrder returns false if an order with the same order_uid already exists.
+func (o *OrderRepository) insertOrder(ctx context.Context, ext RepoExtension, order model.Order, hash string) (bool, error) {
 	if ext == nil {
 		ext = o.db
 	}
@@ -156,8 +350,53 @@ func (o *OrderRepository) insertOrder(ctx context.Context, ext RepoExtension, or
 		                    shardkey, 
 		                    sm_id, 
 		                    date_created, 
-		                    oof_shard)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11);
+		                    oof_shard,
+		                    content_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (order_uid) DO NOTHING;
+	`
+
+	tag, err := ext.Exec(ctx, query,
+		order.OrderUID,
+		order.TrackNumber,
+		order.Entry,
+		order.Locale,
+		order.InternalSignature,
+		order.CustomerID,
+		order.DeliveryService,
+		order.ShardKey,
+		order.SmID,
+		order.DateCreated,
+		order.OofShard,
+		hash,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (o *OrderRepository) updateOrder(ctx context.Context, ext RepoExtension, order model.Order, hash string) error {
+	if ext == nil {
+		ext = o.db
+	}
+
+	const query = `
+		UPDATE orders
+		SET track_number       = $2,
+		    entry              = $3,
+		    locale             = $4,
+		    internal_signature = $5,
+		    customer_id        = $6,
+		    delivery_service   = $7,
+		    shardkey           = $8,
+		    sm_id              = $9,
+		    date_created       = $10,
+		    oof_shard          = $11,
+		    content_hash       = $12,
+		    updated_at         = now()
+		WHERE order_uid = $1;
 	`
 
 	_, err := ext.Exec(ctx, query,
@@ -172,6 +411,7 @@ func (o *OrderRepository) insertOrder(ctx context.Context, ext RepoExtension, or
 		order.SmID,
 		order.DateCreated,
 		order.OofShard,
+		hash,
 	)
 	if err != nil {
 		return err
@@ -180,20 +420,115 @@ func (o *OrderRepository) insertOrder(ctx context.Context, ext RepoExtension, or
 	return nil
 }
 
+// selectContentHash locks the order row and returns its content hash. Orders stored before
+// content hashes were introduced get it calculated from the stored data. Erased orders have no
+// content hash and return apperrors.ErrOrderErased, they can not be replaced.
+func (o *OrderRepository) selectContentHash(ctx context.Context, ext RepoExtension, orderUID string) (string, error) {
+	if ext == nil {
+		ext = o.db
+	}
+
+	const query = `
+		SELECT content_hash, erased_at IS NOT NULL
+		FROM orders
+		WHERE order_uid = $1
+		FOR UPDATE;
+	`
+
+	var (
+		hash   *string
+		erased bool
+	)
+
+	if err := ext.QueryRow(ctx, query, orderUID).Scan(&hash, &erased); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", apperrors.ErrOrderNotFound
+		}
+
+		return "", err
+	}
+
+	if erased {
+		return "", fmt.Errorf("%w: order_uid %s", apperrors.ErrOrderErased, orderUID)
+	}
+
+	if hash != nil {
+		return *hash, nil
+	}
+
+	order, err := o.selectOrder(ctx, ext, orderUID)
+	if err != nil {
+		return "", err
+	}
+
+	if order.Delivery, err = o.selectDelivery(ctx, ext, orderUID); err != nil {
+		return "", err
+	}
+
+	if order.Payment, err = o.selectPayment(ctx, ext, orderUID); err != nil {
+		return "", err
+	}
+
+	if order.Items, err = o.selectItems(ctx, ext, orderUID); err != nil {
+		return "", err
+	}
+
+	return contentHash(order)
+}
+
+func (o *OrderRepository) deleteOrderDetails(ctx context.Context, ext RepoExtension, orderUID string) error {
+	if ext == nil {
+		ext = o.db
+	}
+
+	for _, query := range []string{
+		`DELETE FROM deliveries WHERE order_uid = $1;`,
+		`DELETE FROM payments WHERE order_uid = $1;`,
+		`DELETE FROM items WHERE order_uid = $1;`,
+	} {
+		if _, err := ext.Exec(ctx, query, orderUID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (o *OrderRepository) selectOrder(ctx context.Context, ext RepoExtension, OrderUID string) (model.Order, error) {
 	if ext == nil {
 		ext = o.db
 	}
 
 	const query = `
-		SELECT order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE order_uid = $1;
 	`
 
-	var order model.Order
+	order, err := scanOrder(ext.QueryRow(ctx, query, OrderUID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return model.Order{}, apperrors.ErrOrderNotFound
+		}
+
+		return model.Order{}, err
+	}
+
+	return order, nil
+}
+
+const orderColumns = `order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shardkey, sm_id, date_created, oof_shard,
+		       cancelled_at, cancel_reason, updated_at`
+
+// scanOrder scans a row selected with orderColumns.
+func scanOrder(row pgx.Row) (model.Order, error) {
+	var (
+		order        model.Order
+		cancelledAt  *time.Time
+		cancelReason *string
+	)
 
-	err := ext.QueryRow(ctx, query, OrderUID).Scan(
+	err := row.Scan(
 		&order.OrderUID,
 		&order.TrackNumber,
 		&order.Entry,
@@ -205,15 +540,22 @@ func (o *OrderRepository) selectOrder(ctx context.Context, ext RepoExtension, Or
 		&order.SmID,
 		&order.DateCreated,
 		&order.OofShard,
+		&cancelledAt,
+		&cancelReason,
+		&order.UpdatedAt,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return model.Order{}, apperrors.ErrOrderNotFound
-		}
-
 		return model.Order{}, err
 	}
 
+	if cancelledAt != nil {
+		order.Cancellation = &model.Cancellation{CancelledAt: *cancelledAt}
+
+		if cancelReason != nil {
+			order.Cancellation.Reason = *cancelReason
+		}
+	}
+
 	return order, nil
 }
 
@@ -257,7 +599,16 @@ func (o *OrderRepository) selectDelivery(ctx context.Context, ext RepoExtension,
 
 	var delivery model.Delivery
 
-	err := ext.QueryRow(ctx, query, orderUID).Scan(
+	err := ext.QueryRow(ctx, query, orderUID).Scan(deliveryFields(&delivery)...)
+	if err != nil {
+		return model.Delivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func deliveryFields(delivery *model.Delivery) []any {
+	return []any{
 		&delivery.Name,
 		&delivery.Phone,
 		&delivery.Zip,
@@ -265,12 +616,7 @@ func (o *OrderRepository) selectDelivery(ctx context.Context, ext RepoExtension,
 		&delivery.Address,
 		&delivery.Region,
 		&delivery.Email,
-	)
-	if err != nil {
-		return model.Delivery{}, err
 	}
-
-	return delivery, nil
 }
 
 func (o *OrderRepository) insertPayment(ctx context.Context, ext RepoExtension, orderUID string, payment model.Payment) error {
@@ -326,7 +672,16 @@ func (o *OrderRepository) selectPayment(ctx context.Context, ext RepoExtension,
 
 	var payment model.Payment
 
-	err := ext.QueryRow(ctx, query, orderUID).Scan(
+	err := ext.QueryRow(ctx, query, orderUID).Scan(paymentFields(&payment)...)
+	if err != nil {
+		return model.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+func paymentFields(payment *model.Payment) []any {
+	return []any{
 		&payment.Transaction,
 		&payment.RequestID,
 		&payment.Currency,
@@ -337,12 +692,7 @@ func (o *OrderRepository) selectPayment(ctx context.Context, ext RepoExtension,
 		&payment.DeliveryCost,
 		&payment.GoodsTotal,
 		&payment.CustomFee,
-	)
-	if err != nil {
-		return model.Payment{}, err
 	}
-
-	return payment, nil
 }
 
 func (o *OrderRepository) insertItems(ctx context.Context, ext RepoExtension, orderUID string, items []model.Item) error {
@@ -406,7 +756,8 @@ func (o *OrderRepository) selectItems(ctx context.Context, ext RepoExtension, or
 	const query = `
 		SELECT chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
 		FROM items
-		WHERE order_uid = $1;
+		WHERE order_uid = $1
+		ORDER BY id;
 	`
 
 	var items []model.Item
@@ -421,20 +772,7 @@ func (o *OrderRepository) selectItems(ctx context.Context, ext RepoExtension, or
 	for rows.Next() {
 		var item model.Item
 
-		err := rows.Scan(
-			&item.ChrtID,
-			&item.TrackNumber,
-			&item.Price,
-			&item.RID,
-			&item.Name,
-			&item.Sale,
-			&item.Size,
-			&item.TotalPrice,
-			&item.NmID,
-			&item.Brand,
-			&item.Status,
-		)
-		if err != nil {
+		if err := rows.Scan(itemFields(&item)...); err != nil {
 			return nil, err
 		}
 
@@ -447,3 +785,19 @@ func (o *OrderRepository) selectItems(ctx context.Context, ext RepoExtension, or
 
 	return items, nil
 }
+
+func itemFields(item *model.Item) []any {
+	return []any{
+		&item.ChrtID,
+		&item.TrackNumber,
+		&item.Price,
+		&item.RID,
+		&item.Name,
+		&item.Sale,
+		&item.Size,
+		&item.TotalPrice,
+		&item.NmID,
+		&item.Brand,
+		&item.Status,
+	}
+}
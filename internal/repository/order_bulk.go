@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// PutOrders stores new orders in a single transaction using a multi-row insert for orders
+// and COPY for their details. Orders whose order_uid is already stored (or repeated within
+// the batch) are not written, their indexes are returned so the caller can store them
+// one by one with PutOrder, which resolves duplicates and conflicts.
+func (o *OrderRepository) PutOrders(ctx context.Context, orders []model.Order) ([]int, error) {
+	if len(orders) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]string, len(orders))
+
+	for i, order := range orders {
+		hash, err := contentHash(order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate content hash: %w", err)
+		}
+
+		hashes[i] = hash
+	}
+
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	inserted, err := o.insertOrders(ctx, tx, orders, hashes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert orders: %w", err)
+	}
+
+	fresh := make([]model.Order, 0, len(orders))
+
+	var existing []int
+
+	for i, order := range orders {
+		if _, ok := inserted[order.OrderUID]; !ok {
+			existing = append(existing, i)
+
+			continue
+		}
+
+		// Only the first order with the given order_uid has been inserted.
+		delete(inserted, order.OrderUID)
+
+		fresh = append(fresh, order)
+	}
+
+	if err = o.copyOrderDetails(ctx, tx, fresh); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return existing, nil
+}
+
+// insertOrders returns the set of order_uids that have been inserted.
+func (o *OrderRepository) insertOrders(ctx context.Context, tx pgx.Tx, orders []model.Order, hashes []string) (map[string]struct{}, error) {
+	const query = `
+		INSERT INTO orders (order_uid,
+		                    track_number,
+		                    entry,
+		                    locale,
+		                    internal_signature,
+		                    customer_id,
+		                    delivery_service,
+		                    shardkey,
+		                    sm_id,
+		                    date_created,
+		                    oof_shard,
+		                    content_hash)
+		SELECT *
+		FROM unnest($1::varchar[], $2::varchar[], $3::varchar[], $4::varchar[], $5::text[], $6::varchar[],
+		            $7::varchar[], $8::varchar[], $9::int[], $10::timestamptz[], $11::varchar[], $12::char(64)[])
+		ON CONFLICT (order_uid) DO NOTHING
+		RETURNING order_uid;
+	`
+
+	var (
+		uids               = make([]string, len(orders))
+		trackNumbers       = make([]string, len(orders))
+		entries            = make([]string, len(orders))
+		locales            = make([]string, len(orders))
+		internalSignatures = make([]string, len(orders))
+		customerIDs        = make([]string, len(orders))
+		deliveryServices   = make([]string, len(orders))
+		shardKeys          = make([]string, len(orders))
+		smIDs              = make([]int, len(orders))
+		datesCreated       = make([]time.Time, len(orders))
+		oofShards          = make([]string, len(orders))
+	)
+
+	for i, order := range orders {
+		uids[i] = order.OrderUID
+		trackNumbers[i] = order.TrackNumber
+		entries[i] = order.Entry
+		locales[i] = order.Locale
+		internalSignatures[i] = order.InternalSignature
+		customerIDs[i] = order.CustomerID
+		deliveryServices[i] = order.DeliveryService
+		shardKeys[i] = order.ShardKey
+		smIDs[i] = order.SmID
+		datesCreated[i] = order.DateCreated
+		oofShards[i] = order.OofShard
+	}
+
+	rows, err := tx.Query(ctx, query,
+		uids,
+		trackNumbers,
+		entries,
+		locales,
+		internalSignatures,
+		customerIDs,
+		deliveryServices,
+		shardKeys,
+		smIDs,
+		datesCreated,
+		oofShards,
+		hashes,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	inserted, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]struct{}, len(inserted))
+	for _, uid := range inserted {
+		result[uid] = struct{}{}
+	}
+
+	return result, nil
+}
+
+func (o *OrderRepository) copyOrderDetails(ctx context.Context, tx pgx.Tx, orders []model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	deliveries := make([][]any, 0, len(orders))
+	payments := make([][]any, 0, len(orders))
+	items := make([][]any, 0, len(orders))
+
+	for _, order := range orders {
+		d := order.Delivery
+		deliveries = append(deliveries, []any{
+			order.OrderUID, d.Name, d.Phone, d.Zip, d.City, d.Address, d.Region, d.Email,
+		})
+
+		p := order.Payment
+		payments = append(payments, []any{
+			order.OrderUID, p.Transaction, p.RequestID, p.Currency, p.Provider, p.Amount,
+			p.PaymentDt, p.Bank, p.DeliveryCost, p.GoodsTotal, p.CustomFee,
+		})
+
+		for _, v := range order.Items {
+			items = append(items, []any{
+				order.OrderUID, v.ChrtID, v.TrackNumber, v.Price, v.RID, v.Name,
+				v.Sale, v.Size, v.TotalPrice, v.NmID, v.Brand, v.Status,
+			})
+		}
+	}
+
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"deliveries"},
+		[]string{"order_uid", "name", "phone", "zip", "city", "address", "region", "email"},
+		pgx.CopyFromRows(deliveries),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy deliveries: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"payments"},
+		[]string{
+			"order_uid", "transaction", "request_id", "currency", "provider", "amount",
+			"payment_dt", "bank", "delivery_cost", "goods_total", "custom_fee",
+		},
+		pgx.CopyFromRows(payments),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy payments: %w", err)
+	}
+
+	_, err = tx.CopyFrom(ctx, pgx.Identifier{"items"},
+		[]string{
+			"order_uid", "chrt_id", "track_number", "price", "rid", "name",
+			"sale", "size", "total_price", "nm_id", "brand", "status",
+		},
+		pgx.CopyFromRows(items),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to copy items: %w", err)
+	}
+
+	return nil
+}
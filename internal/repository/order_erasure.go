@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// EraseOrders irreversibly replaces personal data of the delivery with model.ErasedValue in every
+// order of the subject and records an audit entry. Already erased orders are not changed, so
+// repeating a request is safe. The content hash is dropped as well, it is derived from the data.
+func (o *OrderRepository) EraseOrders(ctx context.Context, req model.ErasureRequest) (model.ErasureResult, error) {
+	tx, err := o.db.Begin(ctx)
+	if err != nil {
+		return model.ErasureResult{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	result := model.ErasureResult{
+		Subject:   req.Subject,
+		SubjectID: req.SubjectID,
+		Erased:    []string{},
+		Skipped:   []string{},
+	}
+
+	if err = o.selectErasureOrders(ctx, tx, req, &result); err != nil {
+		return model.ErasureResult{}, fmt.Errorf("failed to select orders: %w", err)
+	}
+
+	if len(result.Erased) > 0 {
+		if err = o.eraseDeliveries(ctx, tx, result.Erased); err != nil {
+			return model.ErasureResult{}, fmt.Errorf("failed to erase deliveries: %w", err)
+		}
+	}
+
+	const query = `
+		INSERT INTO erasure_audit (subject_type, subject_id, requested_by, on_behalf_of, reason, erased, skipped)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at;
+	`
+
+	err = tx.QueryRow(ctx, query, req.Subject, req.SubjectID, req.RequestedBy, req.OnBehalfOf, req.Reason, result.Erased, result.Skipped).
+		Scan(&result.AuditID, &result.ErasedAt)
+	if err != nil {
+		return model.ErasureResult{}, fmt.Errorf("failed to insert audit entry: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return model.ErasureResult{}, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// selectErasureOrders locks the orders of the subject, so they are not replaced while being erased.
+func (o *OrderRepository) selectErasureOrders(ctx context.Context, tx pgx.Tx, req model.ErasureRequest, result *model.ErasureResult) error {
+	column := "customer_id"
+	if req.Subject == model.ErasureSubjectOrder {
+		column = "order_uid"
+	}
+
+	query := `
+		SELECT order_uid, erased_at IS NOT NULL
+		FROM orders
+		WHERE ` + column + ` = $1
+		ORDER BY order_uid
+		FOR UPDATE;
+	`
+
+	rows, err := tx.Query(ctx, query, req.SubjectID)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			uid    string
+			erased bool
+		)
+
+		if err = rows.Scan(&uid, &erased); err != nil {
+			return err
+		}
+
+		if erased {
+			result.Skipped = append(result.Skipped, uid)
+		} else {
+			result.Erased = append(result.Erased, uid)
+		}
+	}
+
+	return rows.Err()
+}
+
+func (o *OrderRepository) eraseDeliveries(ctx context.Context, tx pgx.Tx, orderUIDs []string) error {
+	const query = `
+		WITH erased AS (
+			UPDATE deliveries
+			SET name    = $2,
+			    phone   = $2,
+			    zip     = $2,
+			    address = $2,
+			    email   = $2
+			WHERE order_uid = ANY($1)
+		)
+		UPDATE orders
+		SET erased_at    = now(),
+		    updated_at   = now(),
+		    content_hash = NULL
+		WHERE order_uid = ANY($1);
+	`
+
+	_, err := tx.Exec(ctx, query, orderUIDs, model.ErasedValue)
+
+	return err
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+const exportFetchSize = 500
+
+// ExportOrders calls fn for every order matching the filter in the listing order. Orders are read
+// through a server-side cursor in chunks of exportFetchSize rows, so memory does not depend on
+// the number of orders. Cursor and Limit of the filter are ignored.
+func (o *OrderRepository) ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(model.Order) error) error {
+	where, args := filterConditions(filter)
+
+	direction := "DESC"
+	if filter.Sort == model.SortAsc {
+		direction = "ASC"
+	}
+
+	query := `
+		DECLARE orders_export NO SCROLL CURSOR FOR
+		SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id, o.delivery_service,
+		       o.shardkey, o.sm_id, o.date_created, o.oof_shard, o.cancelled_at, o.cancel_reason, o.updated_at,
+		       d.name, d.phone, d.zip, d.city, d.address, d.region, d.email,
+		       pm.transaction, pm.request_id, pm.currency, pm.provider, pm.amount, pm.payment_dt, pm.bank,
+		       pm.delivery_cost, pm.goods_total, pm.custom_fee,
+		       i.chrt_id, i.track_number, i.price, i.rid, i.name, i.sale, i.size, i.total_price, i.nm_id, i.brand, i.status
+		FROM orders o` + joinPayments(filter) + `
+		JOIN deliveries d ON d.order_uid = o.order_uid
+		JOIN payments pm ON pm.order_uid = o.order_uid
+		LEFT JOIN items i ON i.order_uid = o.order_uid` + whereClause(where) + `
+		ORDER BY o.date_created ` + direction + `, o.order_uid ` + direction + `, i.id;
+	`
+
+	// Cursors only live inside a transaction.
+	tx, err := o.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly, IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to declare cursor: %w", err)
+	}
+
+	var (
+		current *model.Order
+		fetch   = `FETCH ` + strconv.Itoa(exportFetchSize) + ` FROM orders_export;`
+	)
+
+	for {
+		n, err := o.fetchExportRows(ctx, tx, fetch, &current, fn)
+		if err != nil {
+			return err
+		}
+
+		if n < exportFetchSize {
+			break
+		}
+	}
+
+	if current != nil {
+		if err = fn(*current); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// fetchExportRows reads the next chunk of rows. Rows of one order are consecutive, the order is
+// passed to fn once a row of the next order is read, the last one is left in current.
+func (o *OrderRepository) fetchExportRows(ctx context.Context, tx pgx.Tx, fetch string, current **model.Order, fn func(model.Order) error) (int, error) {
+	rows, err := tx.Query(ctx, fetch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+
+	defer rows.Close()
+
+	n := 0
+
+	for rows.Next() {
+		n++
+
+		order, item, err := scanExportRow(rows)
+		if err != nil {
+			return n, fmt.Errorf("failed to scan order: %w", err)
+		}
+
+		if *current != nil && (*current).OrderUID != order.OrderUID {
+			if err := fn(**current); err != nil {
+				return n, err
+			}
+
+			*current = nil
+		}
+
+		if *current == nil {
+			*current = &order
+		}
+
+		if item != nil {
+			(*current).Items = append((*current).Items, *item)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("failed to fetch orders: %w", err)
+	}
+
+	return n, nil
+}
+
+// scanExportRow returns the order of the row and its item, which is nil for orders without items.
+func scanExportRow(rows pgx.Rows) (model.Order, *model.Item, error) {
+	var (
+		order        model.Order
+		cancelledAt  *time.Time
+		cancelReason *string
+
+		chrtID, price, sale, totalPrice, nmID, status *int
+		trackNumber, rid, name, size, brand           *string
+	)
+
+	dest := []any{
+		&order.OrderUID,
+		&order.TrackNumber,
+		&order.Entry,
+		&order.Locale,
+		&order.InternalSignature,
+		&order.CustomerID,
+		&order.DeliveryService,
+		&order.ShardKey,
+		&order.SmID,
+		&order.DateCreated,
+		&order.OofShard,
+		&cancelledAt,
+		&cancelReason,
+		&order.UpdatedAt,
+	}
+	dest = append(dest, deliveryFields(&order.Delivery)...)
+	dest = append(dest, paymentFields(&order.Payment)...)
+	dest = append(dest, &chrtID, &trackNumber, &price, &rid, &name, &sale, &size, &totalPrice, &nmID, &brand, &status)
+
+	if err := rows.Scan(dest...); err != nil {
+		return model.Order{}, nil, err
+	}
+
+	if cancelledAt != nil {
+		order.Cancellation = &model.Cancellation{CancelledAt: *cancelledAt}
+
+		if cancelReason != nil {
+			order.Cancellation.Reason = *cancelReason
+		}
+	}
+
+	if chrtID == nil {
+		return order, nil, nil
+	}
+
+	return order, &model.Item{
+		ChrtID:      *chrtID,
+		TrackNumber: *trackNumber,
+		Price:       *price,
+		RID:         *rid,
+		Name:        *name,
+		Sale:        *sale,
+		Size:        *size,
+		TotalPrice:  *totalPrice,
+		NmID:        *nmID,
+		Brand:       *brand,
+		Status:      *status,
+	}, nil
+}
@@ -0,0 +1,353 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/model"
+)
+
+// listCursor is the position after the last order of a page. It is passed to clients base64 encoded.
+type listCursor struct {
+	DateCreated time.Time `json:"d"`
+	OrderUID    string    `json:"u"`
+}
+
+func encodeCursor(order model.Order) (string, error) {
+	data, err := json.Marshal(listCursor{
+		DateCreated: order.DateCreated,
+		OrderUID:    order.OrderUID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) (listCursor, error) {
+	var c listCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return listCursor{}, apperrors.ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(data, &c); err != nil || c.OrderUID == "" {
+		return listCursor{}, apperrors.ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// ListOrders returns a page of orders matching the filter using keyset pagination on (date_created, order_uid).
+func (o *OrderRepository) ListOrders(ctx context.Context, filter model.OrderFilter) (model.OrderPage, error) {
+	where, args := filterConditions(filter)
+
+	desc := filter.Sort != model.SortAsc
+
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return model.OrderPage{}, err
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+
+		args = append(args, c.DateCreated, c.OrderUID)
+		where = append(where, fmt.Sprintf("(o.date_created, o.order_uid) %s ($%d, $%d)", op, len(args)-1, len(args)))
+	}
+
+	direction := "ASC"
+	if desc {
+		direction = "DESC"
+	}
+
+	// One more row is requested to know whether there is a next page.
+	args = append(args, filter.Limit+1)
+
+	query := `
+		SELECT o.order_uid
+		FROM orders o` + joinPayments(filter) + whereClause(where) + `
+		ORDER BY o.date_created ` + direction + `, o.order_uid ` + direction + `
+		LIMIT $` + strconv.Itoa(len(args)) + `;
+	`
+
+	rows, err := o.db.Query(ctx, query, args...)
+	if err != nil {
+		return model.OrderPage{}, fmt.Errorf("failed to query orders: %w", err)
+	}
+
+	uids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return model.OrderPage{}, fmt.Errorf("failed to collect orders: %w", err)
+	}
+
+	hasNext := len(uids) > filter.Limit
+	if hasNext {
+		uids = uids[:filter.Limit]
+	}
+
+	orders, err := o.GetOrders(ctx, uids)
+	if err != nil {
+		return model.OrderPage{}, err
+	}
+
+	page := model.OrderPage{
+		Orders: orders,
+	}
+
+	if hasNext && len(orders) > 0 {
+		if page.NextCursor, err = encodeCursor(orders[len(orders)-1]); err != nil {
+			return model.OrderPage{}, fmt.Errorf("failed to encode cursor: %w", err)
+		}
+	}
+
+	return page, nil
+}
+
+// filterConditions builds WHERE conditions for the filter over orders aliased as o and payments aliased as p.
+func filterConditions(filter model.OrderFilter) ([]string, []any) {
+	var (
+		where []string
+		args  []any
+	)
+
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.CustomerID != "" {
+		add("o.customer_id = $%d", filter.CustomerID)
+	}
+
+	if filter.DeliveryService != "" {
+		add("o.delivery_service = $%d", filter.DeliveryService)
+	}
+
+	if filter.Locale != "" {
+		add("o.locale = $%d", filter.Locale)
+	}
+
+	if filter.CreatedFrom != nil {
+		add("o.date_created >= $%d", *filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		add("o.date_created < $%d", *filter.CreatedTo)
+	}
+
+	if filter.Currency != "" {
+		add("p.currency = $%d", filter.Currency)
+	}
+
+	if filter.Bank != "" {
+		add("p.bank = $%d", filter.Bank)
+	}
+
+	if filter.Brand != "" {
+		add("EXISTS (SELECT 1 FROM items i WHERE i.order_uid = o.order_uid AND i.brand = $%d)", filter.Brand)
+	}
+
+	return where, args
+}
+
+func joinPayments(filter model.OrderFilter) string {
+	if filter.Currency == "" && filter.Bank == "" {
+		return ""
+	}
+
+	return `
+		JOIN payments p ON p.order_uid = o.order_uid`
+}
+
+func whereClause(where []string) string {
+	if len(where) == 0 {
+		return ""
+	}
+
+	return `
+		WHERE ` + strings.Join(where, " AND ")
+}
+
+// GetOrders returns the stored orders with the given order_uids in the same order,
+// order_uids that are not stored are skipped.
+func (o *OrderRepository) GetOrders(ctx context.Context, orderUIDs []string) ([]model.Order, error) {
+	if len(orderUIDs) == 0 {
+		return []model.Order{}, nil
+	}
+
+	tx, err := o.db.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly, IsoLevel: pgx.RepeatableRead})
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	orders, err := o.selectOrders(ctx, tx, orderUIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select orders: %w", err)
+	}
+
+	if err = o.selectDeliveries(ctx, tx, orderUIDs, orders); err != nil {
+		return nil, fmt.Errorf("failed to select deliveries: %w", err)
+	}
+
+	if err = o.selectPayments(ctx, tx, orderUIDs, orders); err != nil {
+		return nil, fmt.Errorf("failed to select payments: %w", err)
+	}
+
+	if err = o.selectOrderItems(ctx, tx, orderUIDs, orders); err != nil {
+		return nil, fmt.Errorf("failed to select items: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	result := make([]model.Order, 0, len(orders))
+
+	for _, uid := range orderUIDs {
+		if order, ok := orders[uid]; ok {
+			result = append(result, *order)
+			delete(orders, uid)
+		}
+	}
+
+	return result, nil
+}
+
+func (o *OrderRepository) selectOrders(ctx context.Context, ext RepoExtension, orderUIDs []string) (map[string]*model.Order, error) {
+	const query = `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE order_uid = ANY($1);
+	`
+
+	rows, err := ext.Query(ctx, query, orderUIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	orders := make(map[string]*model.Order, len(orderUIDs))
+
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		orders[order.OrderUID] = &order
+	}
+
+	return orders, rows.Err()
+}
+
+func (o *OrderRepository) selectDeliveries(ctx context.Context, ext RepoExtension, orderUIDs []string, orders map[string]*model.Order) error {
+	const query = `
+		SELECT order_uid, name, phone, zip, city, address, region, email
+		FROM deliveries
+		WHERE order_uid = ANY($1);
+	`
+
+	return scanDetails(ctx, ext, query, orderUIDs, func(rows pgx.Rows) error {
+		var (
+			uid      string
+			delivery model.Delivery
+		)
+
+		if err := rows.Scan(append([]any{&uid}, deliveryFields(&delivery)...)...); err != nil {
+			return err
+		}
+
+		if order, ok := orders[uid]; ok {
+			order.Delivery = delivery
+		}
+
+		return nil
+	})
+}
+
+func (o *OrderRepository) selectPayments(ctx context.Context, ext RepoExtension, orderUIDs []string, orders map[string]*model.Order) error {
+	const query = `
+		SELECT order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee
+		FROM payments
+		WHERE order_uid = ANY($1);
+	`
+
+	return scanDetails(ctx, ext, query, orderUIDs, func(rows pgx.Rows) error {
+		var (
+			uid     string
+			payment model.Payment
+		)
+
+		if err := rows.Scan(append([]any{&uid}, paymentFields(&payment)...)...); err != nil {
+			return err
+		}
+
+		if order, ok := orders[uid]; ok {
+			order.Payment = payment
+		}
+
+		return nil
+	})
+}
+
+func (o *OrderRepository) selectOrderItems(ctx context.Context, ext RepoExtension, orderUIDs []string, orders map[string]*model.Order) error {
+	const query = `
+		SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status
+		FROM items
+		WHERE order_uid = ANY($1)
+		ORDER BY id;
+	`
+
+	return scanDetails(ctx, ext, query, orderUIDs, func(rows pgx.Rows) error {
+		var (
+			uid  string
+			item model.Item
+		)
+
+		if err := rows.Scan(append([]any{&uid}, itemFields(&item)...)...); err != nil {
+			return err
+		}
+
+		if order, ok := orders[uid]; ok {
+			order.Items = append(order.Items, item)
+		}
+
+		return nil
+	})
+}
+
+func scanDetails(ctx context.Context, ext RepoExtension, query string, orderUIDs []string, scan func(rows pgx.Rows) error) error {
+	rows, err := ext.Query(ctx, query, orderUIDs)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
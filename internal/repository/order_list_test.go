@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/model"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		order model.Order
+	}{
+		{
+			name:  "utc",
+			order: model.Order{OrderUID: "b563feb7b2b84b6test", DateCreated: time.Date(2021, 11, 26, 6, 22, 19, 0, time.UTC)},
+		},
+		{
+			name:  "nanoseconds and offset",
+			order: model.Order{OrderUID: "uid", DateCreated: time.Date(2024, 2, 29, 23, 59, 59, 123456789, time.FixedZone("MSK", 3*60*60))},
+		},
+		{
+			name:  "uid with symbols",
+			order: model.Order{OrderUID: `a/b+c="d"`, DateCreated: time.Unix(0, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursor, err := encodeCursor(tt.order)
+			if err != nil {
+				t.Fatalf("encodeCursor() error = %v", err)
+			}
+
+			if _, err = base64.RawURLEncoding.DecodeString(cursor); err != nil {
+				t.Errorf("cursor %q is not URL-safe base64: %v", cursor, err)
+			}
+
+			c, err := decodeCursor(cursor)
+			if err != nil {
+				t.Fatalf("decodeCursor() error = %v", err)
+			}
+
+			if c.OrderUID != tt.order.OrderUID || !c.DateCreated.Equal(tt.order.DateCreated) {
+				t.Errorf("decodeCursor() = %+v, want %s at %s", c, tt.order.OrderUID, tt.order.DateCreated)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	encode := func(s string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"u":"uid"}`))},
+		{name: "not JSON", cursor: encode("uid")},
+		{name: "no uid", cursor: encode(`{"d":"2021-11-26T06:22:19Z"}`)},
+		{name: "invalid date", cursor: encode(`{"d":"yesterday","u":"uid"}`)},
+		{name: "wrong types", cursor: encode(`{"d":1,"u":2}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.cursor); !errors.Is(err, apperrors.ErrInvalidCursor) {
+				t.Errorf("decodeCursor() error = %v, want %v", err, apperrors.ErrInvalidCursor)
+			}
+		})
+	}
+}
@@ -8,47 +8,115 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
 
+	"wb-tech-test-assignment/internal/correlation"
 	"wb-tech-test-assignment/internal/model"
 )
 
 const (
 	defaultTTL       = 24 * time.Hour
 	defaultBatchSize = 100
+
+	evictAttempts = 3
+	evictBackoff  = 50 * time.Millisecond
 )
 
 type DefaultOrderRepository interface {
 	PutOrder(ctx context.Context, order model.Order) error
+	PutOrders(ctx context.Context, orders []model.Order) ([]int, error)
+	UpdateOrder(ctx context.Context, order model.Order) error
+	UpdateItemStatus(ctx context.Context, orderUID string, chrtID, status int) error
+	CancelOrder(ctx context.Context, orderUID string, cancellation model.Cancellation) error
 	GetOrder(ctx context.Context, orderUID string) (model.Order, error)
+	GetOrders(ctx context.Context, orderUIDs []string) ([]model.Order, error)
 	GetOrdersBatch(ctx context.Context, limit, offset int) ([]model.Order, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter) (model.OrderPage, error)
+	ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(model.Order) error) error
+	EraseOrders(ctx context.Context, req model.ErasureRequest) (model.ErasureResult, error)
 }
 
 type OrderWithCacheRepository struct {
+	log  *zap.Logger
 	rdb  *redis.Client
 	repo DefaultOrderRepository
 }
 
-func NewOrderWithCacheRepository(rdb *redis.Client, defaultRepo DefaultOrderRepository) *OrderWithCacheRepository {
+func NewOrderWithCacheRepository(log *zap.Logger, rdb *redis.Client, defaultRepo DefaultOrderRepository) *OrderWithCacheRepository {
 	return &OrderWithCacheRepository{
+		log:  log,
 		rdb:  rdb,
 		repo: defaultRepo,
 	}
 }
 
+// PutOrder evicts the order from the cache instead of caching it: the stored row may differ from
+// the order of the message (a replaced order keeps its cancellation, updated_at is set by the DB),
+// so it is cached on the next read.
 func (o *OrderWithCacheRepository) PutOrder(ctx context.Context, order model.Order) error {
 	if err := o.repo.PutOrder(ctx, order); err != nil {
 		return fmt.Errorf("failed to put order in DB: %w", err)
 	}
 
-	data, err := json.Marshal(order)
+	o.evict(ctx, order.OrderUID)
+
+	return nil
+}
+
+func (o *OrderWithCacheRepository) PutOrders(ctx context.Context, orders []model.Order) ([]int, error) {
+	existing, err := o.repo.PutOrders(ctx, orders)
 	if err != nil {
-		return fmt.Errorf("failed to marshal order: %w", err)
+		return nil, fmt.Errorf("failed to put orders in DB: %w", err)
 	}
 
-	if err := o.rdb.Set(ctx, order.OrderUID, data, defaultTTL).Err(); err != nil {
-		return fmt.Errorf("failed to set order in redis: %w", err)
+	skip := make(map[int]struct{}, len(existing))
+	for _, i := range existing {
+		skip[i] = struct{}{}
+	}
+
+	// Stored orders are evicted for the same reason as in PutOrder.
+	stored := make([]string, 0, len(orders)-len(existing))
+
+	for i, order := range orders {
+		if _, ok := skip[i]; !ok {
+			stored = append(stored, order.OrderUID)
+		}
+	}
+
+	if len(stored) > 0 {
+		o.evict(ctx, stored...)
+	}
+
+	return existing, nil
+}
+
+func (o *OrderWithCacheRepository) UpdateOrder(ctx context.Context, order model.Order) error {
+	if err := o.repo.UpdateOrder(ctx, order); err != nil {
+		return fmt.Errorf("failed to update order in DB: %w", err)
+	}
+
+	o.evict(ctx, order.OrderUID)
+
+	return nil
+}
+
+func (o *OrderWithCacheRepository) UpdateItemStatus(ctx context.Context, orderUID string, chrtID, status int) error {
+	if err := o.repo.UpdateItemStatus(ctx, orderUID, chrtID, status); err != nil {
+		return fmt.Errorf("failed to update item status in DB: %w", err)
+	}
+
+	o.evict(ctx, orderUID)
+
+	return nil
+}
+
+func (o *OrderWithCacheRepository) CancelOrder(ctx context.Context, orderUID string, cancellation model.Cancellation) error {
+	if err := o.repo.CancelOrder(ctx, orderUID, cancellation); err != nil {
+		return fmt.Errorf("failed to cancel order in DB: %w", err)
 	}
 
+	o.evict(ctx, orderUID)
+
 	return nil
 }
 
@@ -58,31 +126,194 @@ func (o *OrderWithCacheRepository) GetOrder(ctx context.Context, orderUID string
 	val, err := o.rdb.Get(ctx, orderUID).Result()
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
-			order, err = o.repo.GetOrder(ctx, orderUID)
-			if err != nil {
-				return model.Order{}, fmt.Errorf("failed to get order from DB: %w", err)
-			}
-
-			data, err := json.Marshal(order)
-			if err != nil {
-				return model.Order{}, fmt.Errorf("failed to marshal order: %w", err)
-			}
-
-			if err := o.rdb.Set(ctx, order.OrderUID, data, defaultTTL).Err(); err != nil {
-				return model.Order{}, fmt.Errorf("failed to set order in redis: %w", err)
-			}
-
-			return order, nil
+			return o.loadOrder(ctx, orderUID)
 		}
+
+		return model.Order{}, fmt.Errorf("failed to get order from redis: %w", err)
 	}
 
 	if err := json.Unmarshal([]byte(val), &order); err != nil {
 		return model.Order{}, fmt.Errorf("failed to unmarshal order: %w", err)
 	}
 
+	// Entries cached from messages before orders were evicted on write have no updated_at,
+	// they are replaced with the stored row so that validators of responses match the DB.
+	if order.UpdatedAt.IsZero() {
+		return o.loadOrder(ctx, orderUID)
+	}
+
 	return order, nil
 }
 
+// loadOrder reads the order from the DB and puts it into the cache.
+func (o *OrderWithCacheRepository) loadOrder(ctx context.Context, orderUID string) (model.Order, error) {
+	order, err := o.repo.GetOrder(ctx, orderUID)
+	if err != nil {
+		return model.Order{}, fmt.Errorf("failed to get order from DB: %w", err)
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		return model.Order{}, fmt.Errorf("failed to marshal order: %w", err)
+	}
+
+	if err := o.rdb.Set(ctx, order.OrderUID, data, defaultTTL).Err(); err != nil {
+		return model.Order{}, fmt.Errorf("failed to set order in redis: %w", err)
+	}
+
+	return order, nil
+}
+
+// GetOrders reads the orders from the cache with a single MGET, loads the misses from the DB
+// in one query and puts them into the cache. Stored orders are returned in the order of orderUIDs.
+func (o *OrderWithCacheRepository) GetOrders(ctx context.Context, orderUIDs []string) ([]model.Order, error) {
+	if len(orderUIDs) == 0 {
+		return []model.Order{}, nil
+	}
+
+	vals, err := o.rdb.MGet(ctx, orderUIDs...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders from redis: %w", err)
+	}
+
+	found := make(map[string]model.Order, len(orderUIDs))
+
+	var misses []string
+
+	for i, val := range vals {
+		data, ok := val.(string)
+		if !ok {
+			misses = append(misses, orderUIDs[i])
+
+			continue
+		}
+
+		var order model.Order
+		if err := json.Unmarshal([]byte(data), &order); err != nil || order.UpdatedAt.IsZero() {
+			misses = append(misses, orderUIDs[i])
+
+			continue
+		}
+
+		found[orderUIDs[i]] = order
+	}
+
+	if len(misses) > 0 {
+		loaded, err := o.repo.GetOrders(ctx, misses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get orders from DB: %w", err)
+		}
+
+		if err := o.setOrders(ctx, loaded); err != nil {
+			return nil, err
+		}
+
+		for _, order := range loaded {
+			found[order.OrderUID] = order
+		}
+	}
+
+	orders := make([]model.Order, 0, len(found))
+
+	for _, uid := range orderUIDs {
+		if order, ok := found[uid]; ok {
+			orders = append(orders, order)
+			delete(found, uid)
+		}
+	}
+
+	return orders, nil
+}
+
+func (o *OrderWithCacheRepository) setOrders(ctx context.Context, orders []model.Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	pipe := o.rdb.Pipeline()
+
+	for _, order := range orders {
+		data, err := json.Marshal(order)
+		if err != nil {
+			return fmt.Errorf("failed to marshal order: %w", err)
+		}
+
+		pipe.Set(ctx, order.OrderUID, data, defaultTTL)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to set orders in redis: %w", err)
+	}
+
+	return nil
+}
+
+// ListOrders is served by the DB, the cache has no secondary indexes.
+func (o *OrderWithCacheRepository) ListOrders(ctx context.Context, filter model.OrderFilter) (model.OrderPage, error) {
+	page, err := o.repo.ListOrders(ctx, filter)
+	if err != nil {
+		return model.OrderPage{}, fmt.Errorf("failed to list orders from DB: %w", err)
+	}
+
+	return page, nil
+}
+
+// ExportOrders is served by the DB, exported orders are not cached.
+func (o *OrderWithCacheRepository) ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(model.Order) error) error {
+	return o.repo.ExportOrders(ctx, filter, fn)
+}
+
+// EraseOrders erases the orders in the DB and evicts all orders of the subject from the cache,
+// including the ones erased before, so a repeated request also repairs a failed eviction.
+func (o *OrderWithCacheRepository) EraseOrders(ctx context.Context, req model.ErasureRequest) (model.ErasureResult, error) {
+	result, err := o.repo.EraseOrders(ctx, req)
+	if err != nil {
+		return model.ErasureResult{}, fmt.Errorf("failed to erase orders in DB: %w", err)
+	}
+
+	if uids := result.OrderUIDs(); len(uids) > 0 {
+		if err = o.rdb.Del(ctx, uids...).Err(); err != nil {
+			return model.ErasureResult{}, fmt.Errorf("failed to delete erased orders from redis: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// evict removes orders changed in the DB from the cache, they are loaded from the DB on the next read.
+// The change is committed at this point, so a failure is not returned: the caller would repeat a write
+// that is already stored. The deletion is retried, stale entries left after that are logged and
+// served until they expire.
+func (o *OrderWithCacheRepository) evict(ctx context.Context, orderUIDs ...string) {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		if err = o.rdb.Del(ctx, orderUIDs...).Err(); err == nil {
+			return
+		}
+
+		if attempt >= evictAttempts || !sleep(ctx, time.Duration(attempt)*evictBackoff) {
+			break
+		}
+	}
+
+	correlation.Logger(ctx, o.log).Error("Failed to evict stored orders from redis, stale entries are served until they expire",
+		zap.Error(err), zap.Strings("order_uids", orderUIDs), zap.Duration("ttl", defaultTTL))
+}
+
+// sleep waits for d and reports whether ctx is still active.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
 func (o *OrderWithCacheRepository) WarmupCache(ctx context.Context) error {
 	offset := 0
 
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+type stubOrderRepository struct {
+	DefaultOrderRepository
+
+	existing []int
+}
+
+func (s *stubOrderRepository) PutOrders(_ context.Context, _ []model.Order) ([]int, error) {
+	return s.existing, nil
+}
+
+func TestPutOrdersEvictionFailure(t *testing.T) {
+	// Nothing listens on the address, so every Del fails.
+	rdb := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer rdb.Close()
+
+	o := NewOrderWithCacheRepository(zap.NewNop(), rdb, &stubOrderRepository{existing: []int{1}})
+
+	existing, err := o.PutOrders(context.Background(), []model.Order{{OrderUID: "a"}, {OrderUID: "b"}})
+	if err != nil {
+		t.Fatalf("PutOrders() error = %v, want nil", err)
+	}
+
+	if !slices.Equal(existing, []int{1}) {
+		t.Errorf("PutOrders() = %v, want [1]", existing)
+	}
+}
@@ -0,0 +1,112 @@
+package rules
+
+import (
+	"fmt"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+// Rule names used in the config.
+const (
+	RulePaymentAmount   = "payment_amount"
+	RuleGoodsTotal      = "goods_total"
+	RuleItemTrackNumber = "item_track_number"
+	RuleItemTotalPrice  = "item_total_price"
+)
+
+// Machine-readable violation codes.
+const (
+	CodePaymentAmountMismatch = "payment_amount_mismatch"
+	CodeGoodsTotalMismatch    = "goods_total_mismatch"
+	CodeTrackNumberMismatch   = "track_number_mismatch"
+	CodeTotalPriceMismatch    = "total_price_mismatch"
+)
+
+const (
+	percent = 100
+
+	// totalPriceTolerance is the allowed rounding error of the discounted item price.
+	totalPriceTolerance = 1
+)
+
+var defaultRules = []Rule{
+	{Name: RulePaymentAmount, Check: checkPaymentAmount},
+	{Name: RuleGoodsTotal, Check: checkGoodsTotal},
+	{Name: RuleItemTrackNumber, Check: checkItemTrackNumber},
+	{Name: RuleItemTotalPrice, Check: checkItemTotalPrice},
+}
+
+// checkPaymentAmount: payment.amount == goods_total + delivery_cost + custom_fee.
+func checkPaymentAmount(order model.Order) []Violation {
+	p := order.Payment
+
+	expected := p.GoodsTotal + p.DeliveryCost + p.CustomFee
+	if p.Amount == expected {
+		return nil
+	}
+
+	return []Violation{{
+		Field:   "payment.amount",
+		Code:    CodePaymentAmountMismatch,
+		Message: fmt.Sprintf("amount %d must be equal to goods_total + delivery_cost + custom_fee = %d", p.Amount, expected),
+	}}
+}
+
+// checkGoodsTotal: payment.goods_total == sum of items[].total_price.
+func checkGoodsTotal(order model.Order) []Violation {
+	sum := 0
+	for _, item := range order.Items {
+		sum += item.TotalPrice
+	}
+
+	if order.Payment.GoodsTotal == sum {
+		return nil
+	}
+
+	return []Violation{{
+		Field:   "payment.goods_total",
+		Code:    CodeGoodsTotalMismatch,
+		Message: fmt.Sprintf("goods_total %d must be equal to the sum of items total_price %d", order.Payment.GoodsTotal, sum),
+	}}
+}
+
+// checkItemTrackNumber: every item has the track number of the order.
+func checkItemTrackNumber(order model.Order) []Violation {
+	var violations []Violation
+
+	for i, item := range order.Items {
+		if item.TrackNumber == order.TrackNumber {
+			continue
+		}
+
+		violations = append(violations, Violation{
+			Field:   fmt.Sprintf("items[%d].track_number", i),
+			Code:    CodeTrackNumberMismatch,
+			Message: fmt.Sprintf("track_number %q must be equal to the order track_number %q", item.TrackNumber, order.TrackNumber),
+		})
+	}
+
+	return violations
+}
+
+// checkItemTotalPrice: total_price is the price reduced by the sale percent.
+func checkItemTotalPrice(order model.Order) []Violation {
+	var violations []Violation
+
+	for i, item := range order.Items {
+		expected := item.Price * (percent - item.Sale) / percent
+
+		diff := item.TotalPrice - expected
+		if diff >= -totalPriceTolerance && diff <= totalPriceTolerance {
+			continue
+		}
+
+		violations = append(violations, Violation{
+			Field:   fmt.Sprintf("items[%d].total_price", i),
+			Code:    CodeTotalPriceMismatch,
+			Message: fmt.Sprintf("total_price %d must be equal to price %d with sale %d%% = %d", item.TotalPrice, item.Price, item.Sale, expected),
+		})
+	}
+
+	return violations
+}
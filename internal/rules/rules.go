@@ -0,0 +1,106 @@
+// Package rules implements cross-field business validation of orders that cannot be
+// expressed with struct tags. Every rule has a name and can be disabled in the config.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/model"
+)
+
+var ErrUnknownRule = errors.New("unknown rule")
+
+// Violation describes a single failed check.
+type Violation struct {
+	Rule    string `json:"rule"`
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Rule is a named business rule. Check returns no violations if the order satisfies the rule.
+type Rule struct {
+	Name  string
+	Check func(order model.Order) []Violation
+}
+
+// ValidationError is returned when an order violates one or more rules.
+// It matches apperrors.ErrOrderValidation with errors.Is.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("%s: %s (%s)", v.Field, v.Message, v.Code))
+	}
+
+	return "business rules violated: " + strings.Join(parts, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return apperrors.ErrOrderValidation
+}
+
+type Engine struct {
+	rules []Rule
+}
+
+// NewEngine creates an engine with all default rules, except the ones disabled in enabled.
+// Rules missing from enabled are on.
+func NewEngine(enabled map[string]bool) (*Engine, error) {
+	known := make(map[string]struct{}, len(defaultRules))
+	for _, r := range defaultRules {
+		known[r.Name] = struct{}{}
+	}
+
+	for name := range enabled {
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownRule, name)
+		}
+	}
+
+	e := &Engine{}
+
+	for _, r := range defaultRules {
+		if on, ok := enabled[r.Name]; ok && !on {
+			continue
+		}
+
+		e.rules = append(e.rules, r)
+	}
+
+	return e, nil
+}
+
+// Validate runs all enabled rules and returns a *ValidationError with every violation found.
+func (e *Engine) Validate(order model.Order) error {
+	var violations []Violation
+
+	for _, r := range e.rules {
+		for _, v := range r.Check(order) {
+			v.Rule = r.Name
+			violations = append(violations, v)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+
+	return nil
+}
+
+// Rules returns names of the enabled rules.
+func (e *Engine) Rules() []string {
+	names := make([]string, 0, len(e.rules))
+	for _, r := range e.rules {
+		names = append(names, r.Name)
+	}
+
+	return names
+}
@@ -0,0 +1,202 @@
+package rules
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/model"
+)
+
+// validOrder is the sample order of the task: one item of 453 with a 30% sale and a delivery of 1500.
+func validOrder() model.Order {
+	return model.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Payment: model.Payment{
+			Amount:       1817,
+			DeliveryCost: 1500,
+			GoodsTotal:   317,
+		},
+		Items: []model.Item{{
+			ChrtID:      9934930,
+			TrackNumber: "WBILMTESTTRACK",
+			Price:       453,
+			Sale:        30,
+			TotalPrice:  317,
+		}},
+	}
+}
+
+func TestEngineValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled map[string]bool
+		change  func(o *model.Order)
+		// want are the codes of the violations in the order they are reported.
+		want []string
+	}{
+		{
+			name: "valid",
+		},
+		{
+			name: "amount with custom fee",
+			change: func(o *model.Order) {
+				o.Payment.CustomFee = 100
+				o.Payment.Amount += 100
+			},
+		},
+		{
+			name:   "amount mismatch",
+			change: func(o *model.Order) { o.Payment.Amount = 1000 },
+			want:   []string{CodePaymentAmountMismatch},
+		},
+		{
+			name: "goods total of several items",
+			change: func(o *model.Order) {
+				o.Items = append(o.Items, model.Item{TrackNumber: o.TrackNumber, Price: 100, TotalPrice: 100})
+				o.Payment.GoodsTotal += 100
+				o.Payment.Amount += 100
+			},
+		},
+		{
+			name: "goods total mismatch",
+			change: func(o *model.Order) {
+				o.Payment.GoodsTotal = 453
+				o.Payment.Amount = 1953
+			},
+			want: []string{CodeGoodsTotalMismatch},
+		},
+		{
+			name: "track numbers of every item",
+			change: func(o *model.Order) {
+				o.Items[0].TrackNumber = "OTHER"
+				o.Items = append(o.Items, model.Item{TrackNumber: "ANOTHER", Price: 100, TotalPrice: 100})
+				o.Payment.GoodsTotal += 100
+				o.Payment.Amount += 100
+			},
+			want: []string{CodeTrackNumberMismatch, CodeTrackNumberMismatch},
+		},
+		{
+			name: "total price rounding within tolerance",
+			change: func(o *model.Order) {
+				o.Items[0].TotalPrice = 318
+				o.Payment.GoodsTotal = 318
+				o.Payment.Amount = 1818
+			},
+		},
+		{
+			name: "total price mismatch",
+			change: func(o *model.Order) {
+				o.Items[0].TotalPrice = 319
+				o.Payment.GoodsTotal = 319
+				o.Payment.Amount = 1819
+			},
+			want: []string{CodeTotalPriceMismatch},
+		},
+		{
+			name:    "disabled rules",
+			enabled: map[string]bool{RulePaymentAmount: false, RuleGoodsTotal: true},
+			change:  func(o *model.Order) { o.Payment.Amount = 1 },
+		},
+		{
+			name: "all violations",
+			change: func(o *model.Order) {
+				o.Payment.Amount = 1
+				o.Payment.GoodsTotal = 1
+				o.Items[0].TrackNumber = "OTHER"
+				o.Items[0].TotalPrice = 453
+			},
+			want: []string{CodePaymentAmountMismatch, CodeGoodsTotalMismatch, CodeTrackNumberMismatch, CodeTotalPriceMismatch},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEngine(tt.enabled)
+			if err != nil {
+				t.Fatalf("NewEngine() error = %v", err)
+			}
+
+			order := validOrder()
+			if tt.change != nil {
+				tt.change(&order)
+			}
+
+			err = e.Validate(order)
+
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want *ValidationError", err)
+			}
+
+			if !errors.Is(err, apperrors.ErrOrderValidation) {
+				t.Errorf("Validate() error does not match apperrors.ErrOrderValidation")
+			}
+
+			codes := make([]string, 0, len(verr.Violations))
+
+			for _, v := range verr.Violations {
+				if v.Rule == "" || v.Field == "" || v.Message == "" {
+					t.Errorf("incomplete violation %+v", v)
+				}
+
+				codes = append(codes, v.Code)
+			}
+
+			if !slices.Equal(codes, tt.want) {
+				t.Errorf("violation codes = %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewEngine(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled map[string]bool
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "all by default",
+			want: []string{RulePaymentAmount, RuleGoodsTotal, RuleItemTrackNumber, RuleItemTotalPrice},
+		},
+		{
+			name:    "disabled and enabled",
+			enabled: map[string]bool{RuleGoodsTotal: false, RuleItemTotalPrice: true},
+			want:    []string{RulePaymentAmount, RuleItemTrackNumber, RuleItemTotalPrice},
+		},
+		{
+			name:    "unknown rule",
+			enabled: map[string]bool{"delivery_zip": false},
+			wantErr: ErrUnknownRule,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := NewEngine(tt.enabled)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("NewEngine() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if err != nil {
+				return
+			}
+
+			if got := e.Rules(); !slices.Equal(got, tt.want) {
+				t.Errorf("Rules() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/model"
+	"wb-tech-test-assignment/pkg/kafka"
+)
+
+const (
+	defaultBatchMaxSize = 100
+	defaultBatchMaxWait = 50 * time.Millisecond
+)
+
+type batchedOrder struct {
+	msg     *kafka.MessageWithMarkFunc
+	decoded decodedMessage
+}
+
+// batchWorker accumulates order.created events until the batch is full or the oldest one waited
+// for MaxWait and stores them in one transaction. Any other message flushes the batch first,
+// so messages of the same order are still applied in order.
+func (s *OrderService) batchWorker(ctx context.Context, id int, messages <-chan *kafka.MessageWithMarkFunc) {
+	maxSize := s.cfg.Batch.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultBatchMaxSize
+	}
+
+	maxWait := s.cfg.Batch.MaxWait
+	if maxWait <= 0 {
+		maxWait = defaultBatchMaxWait
+	}
+
+	batch := make([]batchedOrder, 0, maxSize)
+
+	var (
+		timer   *time.Timer
+		timeout <-chan time.Time
+	)
+
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+
+		if len(batch) == 0 {
+			return
+		}
+
+		s.flushBatch(ctx, id, batch)
+
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				flush()
+
+				return
+			}
+
+			// The message is decoded once, the result is passed on if it is not batched.
+			decoded := s.decodeMessage(msg.Message)
+			if decoded.err != nil || decoded.event.EventType != model.EventOrderCreated {
+				flush()
+				s.handleDecoded(ctx, id, msg, decoded)
+
+				continue
+			}
+
+			batch = append(batch, batchedOrder{msg: msg, decoded: decoded})
+
+			if len(batch) == 1 {
+				timer = time.NewTimer(maxWait)
+				timeout = timer.C
+			}
+
+			if len(batch) >= maxSize {
+				flush()
+			}
+		case <-timeout:
+			flush()
+		case <-ctx.Done():
+			// The batch can not be stored after shutdown, its messages are not marked and will be redelivered.
+			return
+		}
+	}
+}
+
+// flushBatch stores the batch and marks its messages after the commit. If the batch cannot be
+// stored as a whole, the orders are processed one by one so a single bad order does not
+// send the whole batch to the dead letter topic.
+func (s *OrderService) flushBatch(ctx context.Context, id int, batch []batchedOrder) {
+	orders := make([]model.Order, len(batch))
+	ids := make([]string, len(batch))
+
+	for i, b := range batch {
+		orders[i] = b.decoded.order
+		ids[i] = messageCorrelationID(b.msg.Message)
+	}
+
+	var existing []int
+
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+
+		existing, err = s.orderRepo.PutOrders(ctx, orders)
+
+		return err
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.log.Warn("Failed to store orders batch, falling back to processing one by one",
+			zap.Error(err), zap.Int("worker_id", id), zap.Int("batch_size", len(batch)),
+			zap.Strings("correlation_ids", ids))
+
+		for _, b := range batch {
+			s.handleDecoded(ctx, id, b.msg, b.decoded)
+		}
+
+		return
+	}
+
+	skip := make(map[int]struct{}, len(existing))
+
+	// Already stored orders go through the regular path that resolves duplicates and conflicts.
+	for _, i := range existing {
+		skip[i] = struct{}{}
+
+		s.handleDecoded(ctx, id, batch[i].msg, batch[i].decoded)
+	}
+
+	stored := make([]model.Order, 0, len(batch)-len(existing))
+
+	for i, b := range batch {
+		if _, ok := skip[i]; ok {
+			continue
+		}
+
+		stored = append(stored, b.decoded.order)
+
+		b.msg.Mark()
+	}
+
+	s.hub.publish(stored...)
+
+	s.log.Info("Orders batch processed",
+		zap.Int("worker_id", id), zap.Int("batch_size", len(batch)), zap.Int("existing", len(existing)),
+		zap.Strings("correlation_ids", ids))
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/correlation"
+	"wb-tech-test-assignment/internal/rules"
+)
+
+// Headers attached to every message re-published to the dead letter topic.
+const (
+	HeaderDLQErrorClass      = "dlq-error-class"
+	HeaderDLQErrorCode       = "dlq-error-code"
+	HeaderDLQErrorMessage    = "dlq-error-message"
+	HeaderDLQSourceTopic     = "dlq-source-topic"
+	HeaderDLQSourcePartition = "dlq-source-partition"
+	HeaderDLQSourceOffset    = "dlq-source-offset"
+	HeaderDLQFailedAt        = "dlq-failed-at"
+	HeaderDLQViolations      = "dlq-violations"
+
+	headerDLQPrefix = "dlq-"
+)
+
+// deadLetter re-publishes the original message to the dead letter topic together with
+// the failure details. It is a no-op when the dead letter topic is disabled.
+func (s *OrderService) deadLetter(ctx context.Context, msg *sarama.ConsumerMessage, cause error) error {
+	if s.dlq == nil {
+		return nil
+	}
+
+	headers := make(map[string]string, len(msg.Headers)+8)
+
+	// Keep the original headers so the message can be replayed as is,
+	// but drop details of a previous failure if the message was already replayed once.
+	for _, h := range msg.Headers {
+		if h == nil || strings.HasPrefix(string(h.Key), headerDLQPrefix) {
+			continue
+		}
+
+		headers[string(h.Key)] = string(h.Value)
+	}
+
+	// Messages without a correlation ID get the one generated by the consumer.
+	headers = correlation.Headers(ctx, headers)
+
+	// The class is the kind of the error catalog, the code identifies the error within it.
+	headers[HeaderDLQErrorClass] = string(apperrors.KindOf(cause))
+	headers[HeaderDLQErrorCode] = apperrors.CodeOf(cause)
+	headers[HeaderDLQErrorMessage] = cause.Error()
+	headers[HeaderDLQSourceTopic] = msg.Topic
+	headers[HeaderDLQSourcePartition] = strconv.FormatInt(int64(msg.Partition), 10)
+	headers[HeaderDLQSourceOffset] = strconv.FormatInt(msg.Offset, 10)
+	headers[HeaderDLQFailedAt] = time.Now().UTC().Format(time.RFC3339Nano)
+
+	var validationErr *rules.ValidationError
+	if errors.As(cause, &validationErr) {
+		if data, err := json.Marshal(validationErr.Violations); err == nil {
+			headers[HeaderDLQViolations] = string(data)
+		}
+	}
+
+	partition, offset, err := s.dlq.PushMessageWithHeaders(ctx, msg.Key, msg.Value, headers)
+	if err != nil {
+		return fmt.Errorf("failed to push message to dead letter topic: %w", err)
+	}
+
+	correlation.Logger(ctx, s.log).Warn("Message sent to dead letter topic",
+		zap.String("error_class", headers[HeaderDLQErrorClass]),
+		zap.String("error_code", headers[HeaderDLQErrorCode]),
+		zap.String("source_topic", msg.Topic),
+		zap.Int32("source_partition", msg.Partition),
+		zap.Int64("source_offset", msg.Offset),
+		zap.Int32("dlq_partition", partition),
+		zap.Int64("dlq_offset", offset),
+	)
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/correlation"
+	"wb-tech-test-assignment/internal/model"
+)
+
+type ErasureRepository interface {
+	EraseOrders(ctx context.Context, req model.ErasureRequest) (model.ErasureResult, error)
+}
+
+// OrderForgetter drops erased orders kept in memory.
+type OrderForgetter interface {
+	ForgetOrders(orderUIDs []string)
+}
+
+// ErasureService anonymises personal data of customers on their deletion requests.
+type ErasureService struct {
+	log       *zap.Logger
+	repo      ErasureRepository
+	forgetter OrderForgetter
+}
+
+// NewErasureService creates the erasure service, forgetter is optional.
+func NewErasureService(log *zap.Logger, repo ErasureRepository, forgetter OrderForgetter) *ErasureService {
+	return &ErasureService{
+		log:       log,
+		repo:      repo,
+		forgetter: forgetter,
+	}
+}
+
+// Erase anonymises the orders of the subject. Every request is audited, including repeated ones
+// and requests without matching orders.
+func (s *ErasureService) Erase(ctx context.Context, req model.ErasureRequest) (model.ErasureResult, error) {
+	req.SubjectID = strings.TrimSpace(req.SubjectID)
+	req.RequestedBy = strings.TrimSpace(req.RequestedBy)
+	req.OnBehalfOf = strings.TrimSpace(req.OnBehalfOf)
+
+	switch {
+	case req.Subject != model.ErasureSubjectCustomer && req.Subject != model.ErasureSubjectOrder:
+		return model.ErasureResult{}, fmt.Errorf("%w: unknown subject %q", apperrors.ErrInvalidErasure, req.Subject)
+	case req.SubjectID == "":
+		return model.ErasureResult{}, fmt.Errorf("%w: subject id is required", apperrors.ErrInvalidErasure)
+	case req.RequestedBy == "":
+		return model.ErasureResult{}, fmt.Errorf("%w: requested by is required", apperrors.ErrInvalidErasure)
+	}
+
+	result, err := s.repo.EraseOrders(ctx, req)
+	if err != nil {
+		return model.ErasureResult{}, fmt.Errorf("failed to erase orders: %w", err)
+	}
+
+	if s.forgetter != nil {
+		s.forgetter.ForgetOrders(result.OrderUIDs())
+	}
+
+	correlation.Logger(ctx, s.log).Info("Personal data erased",
+		zap.Int64("audit_id", result.AuditID),
+		zap.String("subject", string(req.Subject)),
+		zap.String("requested_by", req.RequestedBy),
+		zap.String("on_behalf_of", req.OnBehalfOf),
+		zap.Int("erased", len(result.Erased)),
+		zap.Int("skipped", len(result.Skipped)),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/codec"
+	"wb-tech-test-assignment/internal/correlation"
+	"wb-tech-test-assignment/internal/model"
+)
+
+// decodeEvent decodes a message from the orders topic with the codec selected by its content-type header.
+func (s *OrderService) decodeEvent(msg *sarama.ConsumerMessage) (model.OrderEvent, error) {
+	return s.decode(headerValue(msg, codec.HeaderContentType), msg.Value)
+}
+
+// decode decodes and validates an event with the codec selected by the content type.
+func (s *OrderService) decode(contentType string, data []byte) (model.OrderEvent, error) {
+	c, err := s.codecs.Lookup(contentType)
+	if err != nil {
+		return model.OrderEvent{}, fmt.Errorf("%w: %w", apperrors.ErrOrderDecode, err)
+	}
+
+	event, err := c.Decode(data)
+	if err != nil {
+		return model.OrderEvent{}, fmt.Errorf("%w: %s codec: %w", apperrors.ErrOrderDecode, c.Name(), err)
+	}
+
+	if err := s.validate.Struct(event); err != nil {
+		return model.OrderEvent{}, fmt.Errorf("%w: failed to validate event: %w", apperrors.ErrOrderDecode, err)
+	}
+
+	return event, nil
+}
+
+func headerValue(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if h != nil && strings.EqualFold(string(h.Key), key) {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+// messageCorrelationID returns the correlation ID of the message set by its producer. Messages
+// without a valid one get an ID built from their position, so that redeliveries share it.
+func messageCorrelationID(msg *sarama.ConsumerMessage) string {
+	if id := headerValue(msg, correlation.HeaderKafka); correlation.Valid(id) {
+		return id
+	}
+
+	return fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset)
+}
+
+// decodedEvent is an event with the order of order.created and order.updated events decoded and validated.
+type decodedEvent struct {
+	event model.OrderEvent
+	order model.Order
+}
+
+// decodedMessage is a message of the orders topic decoded once by the worker, err is the decoding
+// error reported by handleMessage.
+type decodedMessage struct {
+	decodedEvent
+
+	err error
+}
+
+func (s *OrderService) decodeMessage(msg *sarama.ConsumerMessage) decodedMessage {
+	event, err := s.decodeEvent(msg)
+	if err != nil {
+		return decodedMessage{err: err}
+	}
+
+	d, err := s.decodeOrderEvent(event)
+
+	return decodedMessage{decodedEvent: d, err: err}
+}
+
+// decodeOrderEvent decodes the order of order.created and order.updated events, the payloads of
+// other events are decoded by their handlers.
+func (s *OrderService) decodeOrderEvent(event model.OrderEvent) (decodedEvent, error) {
+	d := decodedEvent{event: event}
+
+	if event.EventType != model.EventOrderCreated && event.EventType != model.EventOrderUpdated {
+		return d, nil
+	}
+
+	var err error
+
+	d.order, err = s.decodeOrder(event.Payload)
+
+	return d, err
+}
+
+// handleEvent applies the event to the storage and returns the order_uid it refers to.
+// An order.created event of an already stored order returns apperrors.ErrOrderAlreadyExists.
+func (s *OrderService) handleEvent(ctx context.Context, event model.OrderEvent) (string, error) {
+	d, err := s.decodeOrderEvent(event)
+	if err != nil {
+		return d.order.OrderUID, err
+	}
+
+	return s.applyEvent(ctx, d)
+}
+
+// applyEvent is handleEvent for a decoded event.
+func (s *OrderService) applyEvent(ctx context.Context, d decodedEvent) (string, error) {
+	switch event := d.event; event.EventType {
+	case model.EventOrderCreated:
+		return s.handleOrderCreated(ctx, d.order)
+	case model.EventOrderUpdated:
+		return s.handleOrderUpdated(ctx, d.order)
+	case model.EventOrderItemStatusChanged:
+		return s.handleItemStatusChanged(ctx, event)
+	case model.EventOrderCancelled:
+		return s.handleOrderCancelled(ctx, event)
+	default:
+		return "", fmt.Errorf("%w: unknown event type %q", apperrors.ErrOrderDecode, event.EventType)
+	}
+}
+
+// checkEvent validates the event payload the same way handleEvent does, without applying it.
+func (s *OrderService) checkEvent(event model.OrderEvent) (string, error) {
+	switch event.EventType {
+	case model.EventOrderCreated, model.EventOrderUpdated:
+		order, err := s.decodeOrder(event.Payload)
+
+		return order.OrderUID, err
+	case model.EventOrderItemStatusChanged:
+		var change model.ItemStatusChange
+		err := s.decodePayload(event.Payload, &change)
+
+		return change.OrderUID, err
+	case model.EventOrderCancelled:
+		var cancellation model.OrderCancellation
+		err := s.decodePayload(event.Payload, &cancellation)
+
+		return cancellation.OrderUID, err
+	default:
+		return "", fmt.Errorf("%w: unknown event type %q", apperrors.ErrOrderDecode, event.EventType)
+	}
+}
+
+func (s *OrderService) handleOrderCreated(ctx context.Context, order model.Order) (string, error) {
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		return s.orderRepo.PutOrder(ctx, order)
+	})
+	if err != nil {
+		return order.OrderUID, fmt.Errorf("failed to put order: %w", err)
+	}
+
+	s.hub.publish(order)
+
+	return order.OrderUID, nil
+}
+
+func (s *OrderService) handleOrderUpdated(ctx context.Context, order model.Order) (string, error) {
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		return s.orderRepo.UpdateOrder(ctx, order)
+	})
+	if err != nil {
+		return order.OrderUID, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	s.hub.publish(order)
+
+	return order.OrderUID, nil
+}
+
+func (s *OrderService) handleItemStatusChanged(ctx context.Context, event model.OrderEvent) (string, error) {
+	var change model.ItemStatusChange
+	if err := s.decodePayload(event.Payload, &change); err != nil {
+		return change.OrderUID, err
+	}
+
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		return s.orderRepo.UpdateItemStatus(ctx, change.OrderUID, change.ChrtID, change.Status)
+	})
+	if err != nil {
+		return change.OrderUID, fmt.Errorf("failed to update item status: %w", err)
+	}
+
+	return change.OrderUID, nil
+}
+
+func (s *OrderService) handleOrderCancelled(ctx context.Context, event model.OrderEvent) (string, error) {
+	var cancellation model.OrderCancellation
+	if err := s.decodePayload(event.Payload, &cancellation); err != nil {
+		return cancellation.OrderUID, err
+	}
+
+	err := s.withRetry(ctx, func(ctx context.Context) error {
+		return s.orderRepo.CancelOrder(ctx, cancellation.OrderUID, model.Cancellation{
+			CancelledAt: event.OccurredAt,
+			Reason:      cancellation.Reason,
+		})
+	})
+	if err != nil {
+		return cancellation.OrderUID, fmt.Errorf("failed to cancel order: %w", err)
+	}
+
+	return cancellation.OrderUID, nil
+}
+
+func (s *OrderService) decodeOrder(payload []byte) (model.Order, error) {
+	var order model.Order
+	if err := json.Unmarshal(payload, &order); err != nil {
+		return model.Order{}, fmt.Errorf("%w: failed to unmarshal order: %w", apperrors.ErrOrderDecode, err)
+	}
+
+	order.ClearStoredFields()
+
+	if err := s.validate.Struct(order); err != nil {
+		return order, fmt.Errorf("%w: failed to validate order: %w", apperrors.ErrOrderValidation, err)
+	}
+
+	if err := s.rules.Validate(order); err != nil {
+		return order, fmt.Errorf("failed to validate order: %w", err)
+	}
+
+	return order, nil
+}
+
+func (s *OrderService) decodePayload(payload []byte, v any) error {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("%w: failed to unmarshal payload: %w", apperrors.ErrOrderDecode, err)
+	}
+
+	if err := s.validate.Struct(v); err != nil {
+		return fmt.Errorf("%w: failed to validate payload: %w", apperrors.ErrOrderValidation, err)
+	}
+
+	return nil
+}
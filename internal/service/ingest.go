@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/codec"
+	"wb-tech-test-assignment/internal/correlation"
+	"wb-tech-test-assignment/internal/rules"
+	"wb-tech-test-assignment/pkg/kafka"
+)
+
+var ErrUnknownIngestMode = errors.New("unknown ingest mode")
+
+// IngestMode defines what happens to a message accepted by the HTTP ingestion API.
+type IngestMode string
+
+const (
+	// IngestModePublish publishes the message to the orders topic, it is stored by the consumer.
+	IngestModePublish IngestMode = "publish"
+
+	// IngestModePersist stores the message right away.
+	IngestModePersist IngestMode = "persist"
+)
+
+type IngestStatus string
+
+const (
+	IngestStatusAccepted  IngestStatus = "accepted"
+	IngestStatusDuplicate IngestStatus = "duplicate"
+	IngestStatusRejected  IngestStatus = "rejected"
+	IngestStatusFailed    IngestStatus = "failed"
+)
+
+type IngestResult struct {
+	OrderUID   string            `json:"order_uid,omitempty"`
+	Status     IngestStatus      `json:"status"`
+	ErrorClass string            `json:"error_class,omitempty"`
+	ErrorCode  string            `json:"error_code,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	Violations []rules.Violation `json:"violations,omitempty"`
+}
+
+// IngestService accepts messages from clients that cannot publish to Kafka. Messages are decoded
+// and validated exactly like messages of the orders topic.
+type IngestService struct {
+	log      *zap.Logger
+	orders   *OrderService
+	mode     IngestMode
+	producer kafka.Producer
+}
+
+// NewIngestService creates the ingest service. producer publishes to the orders topic and is required in the publish mode.
+func NewIngestService(log *zap.Logger, orders *OrderService, mode IngestMode, producer kafka.Producer) (*IngestService, error) {
+	switch mode {
+	case IngestModePersist:
+	case IngestModePublish:
+		if producer == nil {
+			return nil, fmt.Errorf("orders producer is required in the %s mode", mode)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownIngestMode, mode)
+	}
+
+	return &IngestService{
+		log:      log,
+		orders:   orders,
+		mode:     mode,
+		producer: producer,
+	}, nil
+}
+
+// Ingest decodes the message with the codec selected by the content type, validates it and then
+// publishes or stores it depending on the mode. A duplicate can only be detected in the persist mode.
+func (s *IngestService) Ingest(ctx context.Context, contentType string, data []byte) IngestResult {
+	event, err := s.orders.decode(contentType, data)
+	if err != nil {
+		return failedResult("", err)
+	}
+
+	orderUID, err := s.orders.checkEvent(event)
+	if err != nil {
+		return failedResult(orderUID, err)
+	}
+
+	if s.mode == IngestModePublish {
+		var headers map[string]string
+		if contentType != "" {
+			headers = map[string]string{codec.HeaderContentType: contentType}
+		}
+
+		headers = correlation.Headers(ctx, headers)
+
+		if _, _, err = s.producer.PushMessageWithHeaders(ctx, []byte(orderUID), data, headers); err != nil {
+			correlation.Logger(ctx, s.log).Error("Failed to publish ingested order", zap.Error(err), zap.String("order_uid", orderUID))
+
+			return failedResult(orderUID, fmt.Errorf("%w: %w", apperrors.ErrOrderPublish, err))
+		}
+
+		return IngestResult{OrderUID: orderUID, Status: IngestStatusAccepted}
+	}
+
+	if _, err = s.orders.handleEvent(ctx, event); err != nil {
+		if errors.Is(err, apperrors.ErrOrderAlreadyExists) {
+			return IngestResult{OrderUID: orderUID, Status: IngestStatusDuplicate}
+		}
+
+		correlation.Logger(ctx, s.log).Warn("Failed to persist ingested order", zap.Error(err), zap.String("order_uid", orderUID))
+
+		return failedResult(orderUID, err)
+	}
+
+	return IngestResult{OrderUID: orderUID, Status: IngestStatusAccepted}
+}
+
+// IngestBatch ingests JSON messages one by one, a failed message does not affect the others.
+func (s *IngestService) IngestBatch(ctx context.Context, messages []json.RawMessage) []IngestResult {
+	results := make([]IngestResult, 0, len(messages))
+
+	for _, msg := range messages {
+		results = append(results, s.Ingest(ctx, codec.ContentTypeJSON, msg))
+	}
+
+	return results
+}
+
+// failedResult reports errors caused by the message itself as rejected and the others as failed.
+// Details of failures are not returned to the client, temporary ones ask it to retry.
+func failedResult(orderUID string, err error) IngestResult {
+	kind := apperrors.KindOf(err)
+
+	result := IngestResult{
+		OrderUID:   orderUID,
+		Status:     IngestStatusRejected,
+		ErrorClass: string(kind),
+		ErrorCode:  apperrors.CodeOf(err),
+		Error:      err.Error(),
+		Violations: violations(err),
+	}
+
+	switch kind {
+	case apperrors.KindValidation, apperrors.KindConflict, apperrors.KindNotFound:
+	case apperrors.KindTransient, apperrors.KindUnavailable:
+		result.Status = IngestStatusFailed
+		result.Error = "service is temporarily unavailable, retry later"
+	default:
+		result.Status = IngestStatusFailed
+		result.Error = "failed to store order"
+	}
+
+	return result
+}
+
+// violations lists failed struct tag checks and business rules of the error.
+func violations(err error) []rules.Violation {
+	var validationErr *rules.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Violations
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil
+	}
+
+	result := make([]rules.Violation, 0, len(fieldErrs))
+	for _, fe := range fieldErrs {
+		result = append(result, rules.Violation{
+			Rule:    "schema",
+			Field:   fe.Namespace(),
+			Code:    fe.Tag(),
+			Message: fe.Error(),
+		})
+	}
+
+	return result
+}
@@ -2,55 +2,91 @@ package service
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/codec"
 	"wb-tech-test-assignment/internal/config"
+	"wb-tech-test-assignment/internal/correlation"
 	"wb-tech-test-assignment/internal/model"
+	"wb-tech-test-assignment/internal/rules"
 	"wb-tech-test-assignment/pkg/kafka"
 	"wb-tech-test-assignment/pkg/postgres"
 )
 
 type OrderRepository interface {
 	PutOrder(ctx context.Context, order model.Order) error
+	PutOrders(ctx context.Context, orders []model.Order) ([]int, error)
+	UpdateOrder(ctx context.Context, order model.Order) error
+	UpdateItemStatus(ctx context.Context, orderUID string, chrtID, status int) error
+	CancelOrder(ctx context.Context, orderUID string, cancellation model.Cancellation) error
 	GetOrder(ctx context.Context, orderUID string) (model.Order, error)
+	GetOrders(ctx context.Context, orderUIDs []string) ([]model.Order, error)
+	ListOrders(ctx context.Context, filter model.OrderFilter) (model.OrderPage, error)
+	ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(model.Order) error) error
+	EraseOrders(ctx context.Context, req model.ErasureRequest) (model.ErasureResult, error)
 }
 
 type OrderService struct {
 	log       *zap.Logger
 	cfg       *config.Subscriber
 	consumer  kafka.ConsumerGroupRunner
+	dlq       kafka.Producer
+	codecs    *codec.Registry
+	rules     *rules.Engine
 	db        postgres.Postgres
 	orderRepo OrderRepository
+	retry     retryPolicy
+	validate  *validator.Validate
+	hub       *orderHub
 }
 
-func NewOrderService(log *zap.Logger, cfg *config.Subscriber, consumer kafka.ConsumerGroupRunner, db postgres.Postgres, orderRepo OrderRepository) *OrderService {
+// NewOrderService creates the order service. dlq may be nil, in which case
+// messages that fail processing are only logged.
+func NewOrderService(log *zap.Logger, cfg *config.Subscriber, consumer kafka.ConsumerGroupRunner, dlq kafka.Producer, codecs *codec.Registry, rulesEngine *rules.Engine, db postgres.Postgres, orderRepo OrderRepository) *OrderService {
 	return &OrderService{
 		log:       log,
 		cfg:       cfg,
 		consumer:  consumer,
+		dlq:       dlq,
+		codecs:    codecs,
+		rules:     rulesEngine,
 		db:        db,
 		orderRepo: orderRepo,
+		retry:     newRetryPolicy(cfg.Retry),
+		validate:  validator.New(),
+		hub:       newOrderHub(),
 	}
 }
 
+// Run consumes the orders topic. Messages are sharded between workers by key, so messages
+// of the same order are always processed by the same worker in the order they were received.
 func (s *OrderService) Run(ctx context.Context) error {
 	s.consumer.Run()
 
 	wg := &sync.WaitGroup{}
 
-	messages := make(chan *kafka.MessageWithMarkFunc, s.cfg.OrdersSubscriber.BufferSize)
-	defer close(messages)
+	shards := make([]chan *kafka.MessageWithMarkFunc, s.cfg.WorkerCount)
+	defer func() {
+		for _, shard := range shards {
+			close(shard)
+		}
+	}()
+
+	shardBufferSize := max(s.cfg.OrdersSubscriber.BufferSize/max(s.cfg.WorkerCount, 1), 1)
 
 	wg.Add(s.cfg.WorkerCount)
 	for i := 0; i < s.cfg.WorkerCount; i++ {
+		shards[i] = make(chan *kafka.MessageWithMarkFunc, shardBufferSize)
+
 		go func() {
 			defer wg.Done()
-			s.worker(ctx, i, messages)
+			s.worker(ctx, i, shards[i])
 
 			s.log.Info("worker finished", zap.Int("worker_id", i))
 		}()
@@ -58,7 +94,7 @@ func (s *OrderService) Run(ctx context.Context) error {
 
 	for msg := range s.consumer.Messages() {
 		select {
-		case messages <- msg:
+		case shards[shardIndex(msg.Message, len(shards))] <- msg:
 		case <-ctx.Done():
 			return ctx.Err()
 		case err := <-s.consumer.Error():
@@ -70,6 +106,8 @@ func (s *OrderService) Run(ctx context.Context) error {
 }
 
 func (s *OrderService) Shutdown() error {
+	s.hub.close()
+
 	err := s.consumer.Shutdown()
 	if err != nil {
 		return fmt.Errorf("failed to shutdown consumer: %w", err)
@@ -87,35 +125,153 @@ func (s *OrderService) GetOrder(ctx context.Context, orderUID string) (model.Ord
 	return order, nil
 }
 
+// WatchOrders subscribes to orders created or updated by this instance of the service until ctx is done.
+// Orders stored after afterSequence are returned in the backlog if they are still kept in memory.
+func (s *OrderService) WatchOrders(ctx context.Context, filter WatchFilter, afterSequence uint64) OrderSubscription {
+	return s.hub.subscribe(ctx, filter, afterSequence)
+}
+
+// ForgetOrders drops the orders from the history of WatchOrders.
+func (s *OrderService) ForgetOrders(orderUIDs []string) {
+	s.hub.forget(orderUIDs)
+}
+
+// LookupOrders returns the stored orders among orderUIDs and the order_uids that are not stored.
+// Repeated order_uids are looked up once.
+func (s *OrderService) LookupOrders(ctx context.Context, orderUIDs []string) (model.OrderLookup, error) {
+	seen := make(map[string]struct{}, len(orderUIDs))
+	uids := make([]string, 0, len(orderUIDs))
+
+	for _, uid := range orderUIDs {
+		if _, ok := seen[uid]; ok {
+			continue
+		}
+
+		seen[uid] = struct{}{}
+		uids = append(uids, uid)
+	}
+
+	orders, err := s.orderRepo.GetOrders(ctx, uids)
+	if err != nil {
+		return model.OrderLookup{}, fmt.Errorf("failed to get orders: %w", err)
+	}
+
+	for _, order := range orders {
+		delete(seen, order.OrderUID)
+	}
+
+	missing := make([]string, 0, len(seen))
+
+	for _, uid := range uids {
+		if _, ok := seen[uid]; ok {
+			missing = append(missing, uid)
+		}
+	}
+
+	return model.OrderLookup{
+		Orders:  orders,
+		Missing: missing,
+	}, nil
+}
+
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 500
+)
+
+// ListOrders returns a page of orders. The limit is set to DefaultListLimit if it is not positive
+// and capped at MaxListLimit.
+func (s *OrderService) ListOrders(ctx context.Context, filter model.OrderFilter) (model.OrderPage, error) {
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = DefaultListLimit
+	case filter.Limit > MaxListLimit:
+		filter.Limit = MaxListLimit
+	}
+
+	page, err := s.orderRepo.ListOrders(ctx, filter)
+	if err != nil {
+		return model.OrderPage{}, fmt.Errorf("failed to list orders: %w", err)
+	}
+
+	return page, nil
+}
+
+// ExportOrders calls fn for every order matching the filter, the cursor and the limit of the filter
+// are ignored. An error returned by fn stops the export and is returned as is.
+func (s *OrderService) ExportOrders(ctx context.Context, filter model.OrderFilter, fn func(model.Order) error) error {
+	if err := s.orderRepo.ExportOrders(ctx, filter, fn); err != nil {
+		return fmt.Errorf("failed to export orders: %w", err)
+	}
+
+	return nil
+}
+
 func (s *OrderService) worker(ctx context.Context, id int, message <-chan *kafka.MessageWithMarkFunc) {
 	s.log.Info("worker start", zap.Int("worker_id", id))
 
+	if s.cfg.Batch.Enable {
+		s.batchWorker(ctx, id, message)
+
+		return
+	}
+
 	for msg := range message {
-		orderUID, err := s.processOrder(ctx, msg.Message.Value)
-		if err != nil {
-			s.log.Error("Failed to process order", zap.Error(err), zap.Int("worker_id", id), zap.String("order_uid", orderUID))
+		s.handleMessage(ctx, id, msg)
+	}
+}
+
+// handleMessage processes a single message and marks it once it is stored or dead-lettered.
+func (s *OrderService) handleMessage(ctx context.Context, id int, msg *kafka.MessageWithMarkFunc) {
+	s.handleDecoded(ctx, id, msg, s.decodeMessage(msg.Message))
+}
+
+// handleDecoded is handleMessage for a message already decoded by the batch worker.
+func (s *OrderService) handleDecoded(ctx context.Context, id int, msg *kafka.MessageWithMarkFunc, d decodedMessage) {
+	ctx = correlation.WithID(ctx, messageCorrelationID(msg.Message))
+	log := correlation.Logger(ctx, s.log)
+
+	orderUID, err := s.processOrder(ctx, d)
+	if err != nil {
+		log.Error("Failed to process order", zap.Error(err), zap.Int("worker_id", id), zap.String("order_uid", orderUID))
+
+		// Processing was interrupted by shutdown, the message will be redelivered.
+		if ctx.Err() != nil || apperrors.KindOf(err) == apperrors.KindUnavailable {
+			return
 		}
 
-		s.log.Info("Order processed", zap.Int("worker_id", id), zap.String("order_uid", orderUID))
+		if err := s.deadLetter(ctx, msg.Message, err); err != nil {
+			// The message is left unmarked so it is redelivered after a restart or rebalance.
+			log.Error("Failed to publish message to dead letter topic", zap.Error(err), zap.Int("worker_id", id),
+				zap.String("topic", msg.Message.Topic),
+				zap.Int32("partition", msg.Message.Partition),
+				zap.Int64("offset", msg.Message.Offset),
+			)
+
+			return
+		}
 
 		msg.Mark()
+
+		return
 	}
+
+	log.Info("Order processed", zap.Int("worker_id", id), zap.String("order_uid", orderUID))
+
+	msg.Mark()
 }
 
-func (s *OrderService) processOrder(ctx context.Context, message []byte) (string, error) {
-	var order model.Order
-	if err := json.Unmarshal(message, &order); err != nil {
-		return "", fmt.Errorf("failed to unmarshal order: %w", err)
+func (s *OrderService) processOrder(ctx context.Context, d decodedMessage) (string, error) {
+	if d.err != nil {
+		return d.order.OrderUID, d.err
 	}
 
-	validate := validator.New()
-	if err := validate.Struct(order); err != nil {
-		return "", fmt.Errorf("failed to validate order: %w", err)
-	}
+	orderUID, err := s.applyEvent(ctx, d.decodedEvent)
+	if errors.Is(err, apperrors.ErrOrderAlreadyExists) {
+		correlation.Logger(ctx, s.log).Debug("Order already stored, skipping", zap.String("order_uid", orderUID))
 
-	if err := s.orderRepo.PutOrder(ctx, order); err != nil {
-		return "", fmt.Errorf("failed to put order: %w", err)
+		return orderUID, nil
 	}
 
-	return order.OrderUID, nil
+	return orderUID, err
 }
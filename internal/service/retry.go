@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/config"
+	"wb-tech-test-assignment/internal/correlation"
+)
+
+const (
+	defaultMaxAttempts    = 5
+	defaultInitialBackoff = 100 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+	backoffMultiplier     = 2
+)
+
+type retryPolicy struct {
+	maxAttempts    int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func newRetryPolicy(cfg config.Retry) retryPolicy {
+	p := retryPolicy{
+		maxAttempts:    cfg.MaxAttempts,
+		initialBackoff: cfg.InitialBackoff,
+		maxBackoff:     cfg.MaxBackoff,
+	}
+
+	if p.maxAttempts <= 0 {
+		p.maxAttempts = defaultMaxAttempts
+	}
+
+	if p.initialBackoff <= 0 {
+		p.initialBackoff = defaultInitialBackoff
+	}
+
+	if p.maxBackoff <= 0 {
+		p.maxBackoff = defaultMaxBackoff
+	}
+
+	return p
+}
+
+// backoff returns the delay before the given retry (starting from 1) using exponential backoff with full jitter.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	d := p.initialBackoff
+	for i := 1; i < retry && d < p.maxBackoff; i++ {
+		d *= backoffMultiplier
+	}
+
+	d = min(d, p.maxBackoff)
+
+	return time.Duration(rand.Int64N(int64(d)) + 1) //nolint:gosec // jitter does not need a cryptographic source
+}
+
+// withRetry calls fn until it succeeds, returns a permanent error or the attempts are exhausted.
+func (s *OrderService) withRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= s.retry.maxAttempts || !isTransient(ctx, err) {
+			break
+		}
+
+		delay := s.retry.backoff(attempt)
+
+		correlation.Logger(ctx, s.log).Warn("Transient error, retrying",
+			zap.Error(err),
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", s.retry.maxAttempts),
+			zap.Duration("backoff", delay),
+		)
+
+		timer := time.NewTimer(delay)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return fmt.Errorf("retry interrupted: %w", errors.Join(ctx.Err(), err))
+		case <-timer.C:
+		}
+	}
+
+	return err
+}
+
+// isTransient reports whether the operation failed with err may succeed if repeated, see apperrors.KindTransient.
+func isTransient(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && apperrors.IsRetryable(err)
+}
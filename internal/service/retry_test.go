@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+
+	"wb-tech-test-assignment/internal/apperrors"
+	"wb-tech-test-assignment/internal/config"
+)
+
+func TestNewRetryPolicy(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Retry
+		want retryPolicy
+	}{
+		{
+			name: "defaults",
+			want: retryPolicy{maxAttempts: 5, initialBackoff: 100 * time.Millisecond, maxBackoff: 5 * time.Second},
+		},
+		{
+			name: "retries disabled",
+			cfg:  config.Retry{MaxAttempts: 1},
+			want: retryPolicy{maxAttempts: 1, initialBackoff: defaultInitialBackoff, maxBackoff: defaultMaxBackoff},
+		},
+		{
+			name: "negative values",
+			cfg:  config.Retry{MaxAttempts: -1, InitialBackoff: -time.Second, MaxBackoff: -time.Second},
+			want: retryPolicy{maxAttempts: defaultMaxAttempts, initialBackoff: defaultInitialBackoff, maxBackoff: defaultMaxBackoff},
+		},
+		{
+			name: "configured",
+			cfg:  config.Retry{MaxAttempts: 3, InitialBackoff: time.Second, MaxBackoff: time.Minute},
+			want: retryPolicy{maxAttempts: 3, initialBackoff: time.Second, maxBackoff: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newRetryPolicy(tt.cfg); got != tt.want {
+				t.Errorf("newRetryPolicy() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := retryPolicy{initialBackoff: 100 * time.Millisecond, maxBackoff: time.Second}
+
+	tests := []struct {
+		retry int
+		// ceiling is the exponential delay the jitter is drawn below.
+		ceiling time.Duration
+	}{
+		{retry: 1, ceiling: 100 * time.Millisecond},
+		{retry: 2, ceiling: 200 * time.Millisecond},
+		{retry: 3, ceiling: 400 * time.Millisecond},
+		{retry: 4, ceiling: 800 * time.Millisecond},
+		{retry: 5, ceiling: time.Second},
+		{retry: 100, ceiling: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.retry), func(t *testing.T) {
+			for range 1000 {
+				d := p.backoff(tt.retry)
+				if d <= 0 || d > tt.ceiling || d > p.maxBackoff {
+					t.Fatalf("backoff(%d) = %s, want within (0, %s]", tt.retry, d, tt.ceiling)
+				}
+			}
+		})
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	transient := &pgconn.PgError{Code: "40001"}
+	permanent := &pgconn.PgError{Code: "23505"}
+
+	tests := []struct {
+		name        string
+		maxAttempts int
+		// failures is the number of calls failing with err before fn succeeds.
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      error
+	}{
+		{name: "success", maxAttempts: 5, wantAttempts: 1},
+		{name: "transient then success", maxAttempts: 5, failures: 2, err: transient, wantAttempts: 3},
+		{name: "attempts exhausted", maxAttempts: 5, failures: 10, err: transient, wantAttempts: 5, wantErr: transient},
+		{name: "default attempts", failures: 10, err: transient, wantAttempts: defaultMaxAttempts, wantErr: transient},
+		{name: "retries disabled", maxAttempts: 1, failures: 10, err: transient, wantAttempts: 1, wantErr: transient},
+		{name: "constraint violation", maxAttempts: 5, failures: 10, err: permanent, wantAttempts: 1, wantErr: permanent},
+		{name: "validation error", maxAttempts: 5, failures: 10, err: apperrors.ErrOrderValidation, wantAttempts: 1, wantErr: apperrors.ErrOrderValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &OrderService{
+				log: zap.NewNop(),
+				retry: newRetryPolicy(config.Retry{
+					MaxAttempts:    tt.maxAttempts,
+					InitialBackoff: time.Millisecond,
+					MaxBackoff:     time.Millisecond,
+				}),
+			}
+
+			attempts := 0
+
+			err := s.withRetry(context.Background(), func(context.Context) error {
+				attempts++
+				if attempts <= tt.failures {
+					return tt.err
+				}
+
+				return nil
+			})
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil) != (err == nil) {
+				t.Errorf("withRetry() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	s := &OrderService{
+		log:   zap.NewNop(),
+		retry: retryPolicy{maxAttempts: 5, initialBackoff: time.Hour, maxBackoff: time.Hour},
+	}
+
+	transient := &pgconn.PgError{Code: "40001"}
+
+	t.Run("during backoff", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		attempts := 0
+
+		err := s.withRetry(ctx, func(context.Context) error {
+			attempts++
+
+			time.AfterFunc(10*time.Millisecond, cancel)
+
+			return transient
+		})
+
+		if attempts != 1 {
+			t.Errorf("attempts = %d, want 1", attempts)
+		}
+
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, transient) {
+			t.Errorf("withRetry() error = %v, want the cancellation and the last error", err)
+		}
+	})
+
+	t.Run("during the call", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		attempts := 0
+
+		err := s.withRetry(ctx, func(context.Context) error {
+			attempts++
+			cancel()
+
+			return transient
+		})
+
+		if attempts != 1 || !errors.Is(err, transient) {
+			t.Errorf("withRetry() = %v after %d attempts, want the error after 1 attempt", err, attempts)
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"strconv"
+
+	"github.com/IBM/sarama"
+)
+
+// shardIndex returns the worker the message is dispatched to. The message key is used when set,
+// otherwise the order_uid from the message body, and the partition as the last resort.
+func shardIndex(msg *sarama.ConsumerMessage, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+
+	key := msg.Key
+	if len(key) == 0 {
+		key = []byte(peekOrderUID(msg.Value))
+	}
+
+	if len(key) == 0 {
+		key = []byte(strconv.FormatInt(int64(msg.Partition), 10))
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+
+	return int(h.Sum32() % uint32(shards)) //nolint:gosec // shards is a positive worker count
+}
+
+// peekOrderUID extracts order_uid from both an event envelope and a legacy bare order
+// without decoding the whole message.
+func peekOrderUID(message []byte) string {
+	var peek struct {
+		OrderUID string `json:"order_uid"`
+		Payload  struct {
+			OrderUID string `json:"order_uid"`
+		} `json:"payload"`
+	}
+
+	if err := json.Unmarshal(message, &peek); err != nil {
+		return ""
+	}
+
+	if peek.Payload.OrderUID != "" {
+		return peek.Payload.OrderUID
+	}
+
+	return peek.OrderUID
+}
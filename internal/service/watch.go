@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+
+	"wb-tech-test-assignment/internal/model"
+)
+
+const (
+	watchHistorySize = 1024
+	watchBufferSize  = 256
+)
+
+// OrderNotification is an order stored by this instance of the service. Sequence numbers grow by one
+// with every stored order from a random base chosen at start, so they are only meaningful to the
+// instance that issued them.
+type OrderNotification struct {
+	Sequence uint64
+	Order    model.Order
+}
+
+// WatchFilter selects notifications, empty fields are not applied.
+type WatchFilter struct {
+	CustomerID      string
+	DeliveryService string
+}
+
+func (f WatchFilter) match(order model.Order) bool {
+	return (f.CustomerID == "" || f.CustomerID == order.CustomerID) &&
+		(f.DeliveryService == "" || f.DeliveryService == order.DeliveryService)
+}
+
+// OrderSubscription delivers notifications matching the filter. Backlog holds the notifications
+// stored after the requested sequence that are still in memory. Resync is set when the requested
+// sequence was not issued by this instance (after a restart or from another replica) or is older than
+// the history: the missed orders are unknown, the subscriber should reload them before using C.
+// C is closed when the context is done or when the subscriber falls behind, in which case it should
+// subscribe again from the last sequence.
+type OrderSubscription struct {
+	Backlog []OrderNotification
+	Resync  bool
+	C       <-chan OrderNotification
+}
+
+type watcher struct {
+	filter WatchFilter
+	ch     chan OrderNotification
+}
+
+// orderHub fans stored orders out to subscribers and keeps the last notifications for resuming.
+type orderHub struct {
+	mu       sync.Mutex
+	sequence uint64
+	// oldest is the lowest sequence a subscriber may resume from without missing notifications.
+	oldest   uint64
+	history  []OrderNotification
+	next     int
+	watchers map[*watcher]struct{}
+	closed   bool
+}
+
+func newOrderHub() *orderHub {
+	// The base keeps sequences of different instances apart, the low half is left for the counter.
+	base := uint64(rand.Uint32()|1) << 32
+
+	return &orderHub{
+		sequence: base,
+		oldest:   base,
+		history:  make([]OrderNotification, 0, watchHistorySize),
+		watchers: make(map[*watcher]struct{}),
+	}
+}
+
+func (h *orderHub) publish(orders ...model.Order) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, order := range orders {
+		h.sequence++
+
+		n := OrderNotification{Sequence: h.sequence, Order: order}
+
+		if len(h.history) < watchHistorySize {
+			h.history = append(h.history, n)
+		} else {
+			h.oldest = h.history[h.next].Sequence
+			h.history[h.next] = n
+			h.next = (h.next + 1) % watchHistorySize
+		}
+
+		for w := range h.watchers {
+			if !w.filter.match(order) {
+				continue
+			}
+
+			select {
+			case w.ch <- n:
+			default:
+				// The subscriber is too slow, it resumes from its last sequence.
+				h.remove(w)
+			}
+		}
+	}
+}
+
+func (h *orderHub) subscribe(ctx context.Context, filter WatchFilter, afterSequence uint64) OrderSubscription {
+	w := &watcher{
+		filter: filter,
+		ch:     make(chan OrderNotification, watchBufferSize),
+	}
+
+	h.mu.Lock()
+
+	var (
+		backlog []OrderNotification
+		resync  bool
+	)
+
+	switch {
+	case afterSequence == 0:
+	case afterSequence < h.oldest || afterSequence > h.sequence:
+		resync = true
+	default:
+		for i := range h.history {
+			n := h.history[(h.next+i)%len(h.history)]
+			if n.Sequence > afterSequence && filter.match(n.Order) {
+				backlog = append(backlog, n)
+			}
+		}
+	}
+
+	if h.closed {
+		close(w.ch)
+	} else {
+		h.watchers[w] = struct{}{}
+	}
+
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		h.mu.Lock()
+		h.remove(w)
+		h.mu.Unlock()
+	}()
+
+	return OrderSubscription{
+		Backlog: backlog,
+		Resync:  resync,
+		C:       w.ch,
+	}
+}
+
+// forget drops the orders from the history, so erased personal data is not sent to resuming subscribers.
+func (h *orderHub) forget(orderUIDs []string) {
+	if len(orderUIDs) == 0 {
+		return
+	}
+
+	uids := make(map[string]struct{}, len(orderUIDs))
+	for _, uid := range orderUIDs {
+		uids[uid] = struct{}{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	kept := make([]OrderNotification, 0, watchHistorySize)
+
+	for i := range h.history {
+		n := h.history[(h.next+i)%len(h.history)]
+		if _, ok := uids[n.Order.OrderUID]; !ok {
+			kept = append(kept, n)
+		}
+	}
+
+	h.history = kept
+	h.next = 0
+}
+
+// close ends all subscriptions, so streams to clients are finished on shutdown.
+func (h *orderHub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+
+	for w := range h.watchers {
+		h.remove(w)
+	}
+}
+
+// remove must be called with the lock held.
+func (h *orderHub) remove(w *watcher) {
+	if _, ok := h.watchers[w]; !ok {
+		return
+	}
+
+	delete(h.watchers, w)
+	close(w.ch)
+}
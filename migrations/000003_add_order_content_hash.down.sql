@@ -0,0 +1,3 @@
+-- 000003_add_order_content_hash.down.sql
+
+ALTER TABLE orders DROP COLUMN IF EXISTS content_hash;
@@ -0,0 +1,3 @@
+-- 000003_add_order_content_hash.up.sql
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS content_hash CHAR(64);
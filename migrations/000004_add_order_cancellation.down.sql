@@ -0,0 +1,6 @@
+-- 000004_add_order_cancellation.down.sql
+
+DROP INDEX IF EXISTS idx_items_order_uid_chrt_id;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS cancel_reason;
+ALTER TABLE orders DROP COLUMN IF EXISTS cancelled_at;
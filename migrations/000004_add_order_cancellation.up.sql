@@ -0,0 +1,6 @@
+-- 000004_add_order_cancellation.up.sql
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMPTZ;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cancel_reason TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_items_order_uid_chrt_id ON items(order_uid, chrt_id);
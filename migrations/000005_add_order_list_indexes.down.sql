@@ -0,0 +1,5 @@
+-- 000005_add_order_list_indexes.down.sql
+
+DROP INDEX IF EXISTS idx_items_brand;
+DROP INDEX IF EXISTS idx_orders_customer_date_created;
+DROP INDEX IF EXISTS idx_orders_date_created_order_uid;
@@ -0,0 +1,5 @@
+-- 000005_add_order_list_indexes.up.sql
+
+CREATE INDEX IF NOT EXISTS idx_orders_date_created_order_uid ON orders(date_created, order_uid);
+CREATE INDEX IF NOT EXISTS idx_orders_customer_date_created ON orders(customer_id, date_created, order_uid);
+CREATE INDEX IF NOT EXISTS idx_items_brand ON items(brand);
@@ -0,0 +1,3 @@
+-- 000006_add_order_updated_at.down.sql
+
+ALTER TABLE orders DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,8 @@
+-- 000006_add_order_updated_at.up.sql
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+UPDATE orders SET updated_at = COALESCE(cancelled_at, date_created) WHERE updated_at IS NULL;
+
+ALTER TABLE orders ALTER COLUMN updated_at SET DEFAULT now();
+ALTER TABLE orders ALTER COLUMN updated_at SET NOT NULL;
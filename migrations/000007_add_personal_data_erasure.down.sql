@@ -0,0 +1,5 @@
+-- 000007_add_personal_data_erasure.down.sql
+
+DROP TABLE IF EXISTS erasure_audit;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS erased_at;
@@ -0,0 +1,16 @@
+-- 000007_add_personal_data_erasure.up.sql
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS erased_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS erasure_audit (
+    id           BIGSERIAL PRIMARY KEY,
+    subject_type VARCHAR(16)  NOT NULL,
+    subject_id   VARCHAR(255) NOT NULL,
+    requested_by VARCHAR(255) NOT NULL,
+    reason       TEXT         NOT NULL DEFAULT '',
+    erased       TEXT[]       NOT NULL,
+    skipped      TEXT[]       NOT NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_erasure_audit_subject ON erasure_audit(subject_type, subject_id);
@@ -0,0 +1,3 @@
+-- 000008_add_order_stored_at.down.sql
+
+ALTER TABLE orders DROP COLUMN IF EXISTS stored_at;
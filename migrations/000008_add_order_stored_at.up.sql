@@ -0,0 +1,10 @@
+-- 000008_add_order_stored_at.up.sql
+
+-- stored_at is the time the current content was stored by order.created, updated_at later than it
+-- means the order was changed by other events since then.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS stored_at TIMESTAMPTZ;
+
+UPDATE orders SET stored_at = CASE WHEN cancelled_at IS NULL THEN updated_at ELSE date_created END WHERE stored_at IS NULL;
+
+ALTER TABLE orders ALTER COLUMN stored_at SET DEFAULT now();
+ALTER TABLE orders ALTER COLUMN stored_at SET NOT NULL;
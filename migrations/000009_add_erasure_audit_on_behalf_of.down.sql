@@ -0,0 +1,3 @@
+-- 000009_add_erasure_audit_on_behalf_of.down.sql
+
+ALTER TABLE erasure_audit DROP COLUMN IF EXISTS on_behalf_of;
@@ -0,0 +1,4 @@
+-- 000009_add_erasure_audit_on_behalf_of.up.sql
+
+-- requested_by is the authenticated client, on_behalf_of is the operator or data subject named by it.
+ALTER TABLE erasure_audit ADD COLUMN IF NOT EXISTS on_behalf_of VARCHAR(255) NOT NULL DEFAULT '';
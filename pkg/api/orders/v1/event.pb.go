@@ -0,0 +1,349 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: orders/v1/event.proto
+
+package ordersv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// OrderEvent is the protobuf form of the orders topic event envelope.
+// Messages must carry the "content-type: application/x-protobuf" header.
+type OrderEvent struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	EventId string                 `protobuf:"bytes,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	// One of: order.created, order.updated, order.item_status_changed, order.cancelled.
+	EventType  string                 `protobuf:"bytes,2,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	OccurredAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*OrderEvent_Order
+	//	*OrderEvent_ItemStatusChange
+	//	*OrderEvent_Cancellation
+	Payload       isOrderEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderEvent) Reset() {
+	*x = OrderEvent{}
+	mi := &file_orders_v1_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderEvent) ProtoMessage() {}
+
+func (x *OrderEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_orders_v1_event_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderEvent.ProtoReflect.Descriptor instead.
+func (*OrderEvent) Descriptor() ([]byte, []int) {
+	return file_orders_v1_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *OrderEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *OrderEvent) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetPayload() isOrderEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetOrder() *Order {
+	if x != nil {
+		if x, ok := x.Payload.(*OrderEvent_Order); ok {
+			return x.Order
+		}
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetItemStatusChange() *ItemStatusChange {
+	if x != nil {
+		if x, ok := x.Payload.(*OrderEvent_ItemStatusChange); ok {
+			return x.ItemStatusChange
+		}
+	}
+	return nil
+}
+
+func (x *OrderEvent) GetCancellation() *OrderCancellation {
+	if x != nil {
+		if x, ok := x.Payload.(*OrderEvent_Cancellation); ok {
+			return x.Cancellation
+		}
+	}
+	return nil
+}
+
+type isOrderEvent_Payload interface {
+	isOrderEvent_Payload()
+}
+
+type OrderEvent_Order struct {
+	Order *Order `protobuf:"bytes,4,opt,name=order,proto3,oneof"`
+}
+
+type OrderEvent_ItemStatusChange struct {
+	ItemStatusChange *ItemStatusChange `protobuf:"bytes,5,opt,name=item_status_change,json=itemStatusChange,proto3,oneof"`
+}
+
+type OrderEvent_Cancellation struct {
+	Cancellation *OrderCancellation `protobuf:"bytes,6,opt,name=cancellation,proto3,oneof"`
+}
+
+func (*OrderEvent_Order) isOrderEvent_Payload() {}
+
+func (*OrderEvent_ItemStatusChange) isOrderEvent_Payload() {}
+
+func (*OrderEvent_Cancellation) isOrderEvent_Payload() {}
+
+type ItemStatusChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderUid      string                 `protobuf:"bytes,1,opt,name=order_uid,json=orderUid,proto3" json:"order_uid,omitempty"`
+	ChrtId        int64                  `protobuf:"varint,2,opt,name=chrt_id,json=chrtId,proto3" json:"chrt_id,omitempty"`
+	Status        int64                  `protobuf:"varint,3,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ItemStatusChange) Reset() {
+	*x = ItemStatusChange{}
+	mi := &file_orders_v1_event_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ItemStatusChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ItemStatusChange) ProtoMessage() {}
+
+func (x *ItemStatusChange) ProtoReflect() protoreflect.Message {
+	mi := &file_orders_v1_event_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ItemStatusChange.ProtoReflect.Descriptor instead.
+func (*ItemStatusChange) Descriptor() ([]byte, []int) {
+	return file_orders_v1_event_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ItemStatusChange) GetOrderUid() string {
+	if x != nil {
+		return x.OrderUid
+	}
+	return ""
+}
+
+func (x *ItemStatusChange) GetChrtId() int64 {
+	if x != nil {
+		return x.ChrtId
+	}
+	return 0
+}
+
+func (x *ItemStatusChange) GetStatus() int64 {
+	if x != nil {
+		return x.Status
+	}
+	return 0
+}
+
+type OrderCancellation struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderUid      string                 `protobuf:"bytes,1,opt,name=order_uid,json=orderUid,proto3" json:"order_uid,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderCancellation) Reset() {
+	*x = OrderCancellation{}
+	mi := &file_orders_v1_event_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderCancellation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderCancellation) ProtoMessage() {}
+
+func (x *OrderCancellation) ProtoReflect() protoreflect.Message {
+	mi := &file_orders_v1_event_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderCancellation.ProtoReflect.Descriptor instead.
+func (*OrderCancellation) Descriptor() ([]byte, []int) {
+	return file_orders_v1_event_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OrderCancellation) GetOrderUid() string {
+	if x != nil {
+		return x.OrderUid
+	}
+	return ""
+}
+
+func (x *OrderCancellation) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_orders_v1_event_proto protoreflect.FileDescriptor
+
+const file_orders_v1_event_proto_rawDesc = "" +
+	"\n" +
+	"\x15orders/v1/event.proto\x12\torders.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x15orders/v1/order.proto\"\xc9\x02\n" +
+	"\n" +
+	"OrderEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x02 \x01(\tR\teventType\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12(\n" +
+	"\x05order\x18\x04 \x01(\v2\x10.orders.v1.OrderH\x00R\x05order\x12K\n" +
+	"\x12item_status_change\x18\x05 \x01(\v2\x1b.orders.v1.ItemStatusChangeH\x00R\x10itemStatusChange\x12B\n" +
+	"\fcancellation\x18\x06 \x01(\v2\x1c.orders.v1.OrderCancellationH\x00R\fcancellationB\t\n" +
+	"\apayload\"`\n" +
+	"\x10ItemStatusChange\x12\x1b\n" +
+	"\torder_uid\x18\x01 \x01(\tR\borderUid\x12\x17\n" +
+	"\achrt_id\x18\x02 \x01(\x03R\x06chrtId\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\x03R\x06status\"H\n" +
+	"\x11OrderCancellation\x12\x1b\n" +
+	"\torder_uid\x18\x01 \x01(\tR\borderUid\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reasonB\x94\x01\n" +
+	"\rcom.orders.v1B\n" +
+	"EventProtoP\x01Z2wb-tech-test-assignment/pkg/api/orders/v1;ordersv1\xa2\x02\x03OXX\xaa\x02\tOrders.V1\xca\x02\tOrders\\V1\xe2\x02\x15Orders\\V1\\GPBMetadata\xea\x02\n" +
+	"Orders::V1b\x06proto3"
+
+var (
+	file_orders_v1_event_proto_rawDescOnce sync.Once
+	file_orders_v1_event_proto_rawDescData []byte
+)
+
+func file_orders_v1_event_proto_rawDescGZIP() []byte {
+	file_orders_v1_event_proto_rawDescOnce.Do(func() {
+		file_orders_v1_event_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_orders_v1_event_proto_rawDesc), len(file_orders_v1_event_proto_rawDesc)))
+	})
+	return file_orders_v1_event_proto_rawDescData
+}
+
+var file_orders_v1_event_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_orders_v1_event_proto_goTypes = []any{
+	(*OrderEvent)(nil),            // 0: orders.v1.OrderEvent
+	(*ItemStatusChange)(nil),      // 1: orders.v1.ItemStatusChange
+	(*OrderCancellation)(nil),     // 2: orders.v1.OrderCancellation
+	(*timestamppb.Timestamp)(nil), // 3: google.protobuf.Timestamp
+	(*Order)(nil),                 // 4: orders.v1.Order
+}
+var file_orders_v1_event_proto_depIdxs = []int32{
+	3, // 0: orders.v1.OrderEvent.occurred_at:type_name -> google.protobuf.Timestamp
+	4, // 1: orders.v1.OrderEvent.order:type_name -> orders.v1.Order
+	1, // 2: orders.v1.OrderEvent.item_status_change:type_name -> orders.v1.ItemStatusChange
+	2, // 3: orders.v1.OrderEvent.cancellation:type_name -> orders.v1.OrderCancellation
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_orders_v1_event_proto_init() }
+func file_orders_v1_event_proto_init() {
+	if File_orders_v1_event_proto != nil {
+		return
+	}
+	file_orders_v1_order_proto_init()
+	file_orders_v1_event_proto_msgTypes[0].OneofWrappers = []any{
+		(*OrderEvent_Order)(nil),
+		(*OrderEvent_ItemStatusChange)(nil),
+		(*OrderEvent_Cancellation)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_orders_v1_event_proto_rawDesc), len(file_orders_v1_event_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_orders_v1_event_proto_goTypes,
+		DependencyIndexes: file_orders_v1_event_proto_depIdxs,
+		MessageInfos:      file_orders_v1_event_proto_msgTypes,
+	}.Build()
+	File_orders_v1_event_proto = out.File
+	file_orders_v1_event_proto_goTypes = nil
+	file_orders_v1_event_proto_depIdxs = nil
+}
@@ -2,6 +2,7 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -17,7 +18,10 @@ const (
 )
 
 type consumerGroupRunner struct {
+	// offsets is the client of the group, it is used to resolve initial offsets of claims.
+	offsets             sarama.Client
 	client              sarama.ConsumerGroup
+	groupID             string
 	consumer            *Consumer
 	ctx                 context.Context
 	cancel              context.CancelFunc
@@ -25,7 +29,9 @@ type consumerGroupRunner struct {
 	infoChan            chan string
 	wg                  sync.WaitGroup
 	topics              []string
+	pauseMu             sync.Mutex
 	consumptionIsPaused bool
+	pausedPartitions    map[string]map[int32]struct{}
 }
 
 func WithBalancerConsumer(b BalanceStrategy) Option {
@@ -53,16 +59,25 @@ func NewConsumerGroupRunner(brokers []string, groupID string, topics []string, b
 		opt(config)
 	}
 
-	client, err := sarama.NewConsumerGroup(brokers, groupID, config)
+	offsets, err := sarama.NewClient(brokers, config)
 	if err != nil {
 		return nil, err
 	}
 
+	client, err := sarama.NewConsumerGroupFromClient(groupID, offsets)
+	if err != nil {
+		_ = offsets.Close()
+
+		return nil, err
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	consumer := NewConsumer(bufferSize)
 
-	return &consumerGroupRunner{
+	runner := &consumerGroupRunner{
+		offsets:             offsets,
 		client:              client,
+		groupID:             groupID,
 		consumer:            consumer,
 		ctx:                 ctx,
 		cancel:              cancel,
@@ -70,7 +85,13 @@ func NewConsumerGroupRunner(brokers []string, groupID string, topics []string, b
 		errChan:             make(chan error, 1),
 		topics:              topics,
 		consumptionIsPaused: false,
-	}, nil
+		pausedPartitions:    make(map[string]map[int32]struct{}),
+	}
+
+	consumer.onClaim = runner.reapplyPause
+	consumer.resolveOffset = offsets.GetOffset
+
+	return runner, nil
 }
 
 func (r *consumerGroupRunner) Run() {
@@ -108,7 +129,8 @@ func (r *consumerGroupRunner) Shutdown() error {
 	r.cancel()
 	r.wg.Wait()
 
-	err := r.client.Close()
+	// A group created from a client does not close it.
+	err := errors.Join(r.client.Close(), r.offsets.Close())
 	close(r.consumer.messages)
 	close(r.errChan)
 	close(r.infoChan)
@@ -116,6 +138,11 @@ func (r *consumerGroupRunner) Shutdown() error {
 	return err
 }
 
+// Stats returns consumption progress: lag per partition, in-flight messages and throughput.
+func (r *consumerGroupRunner) Stats() Stats {
+	return r.consumer.stats.snapshot(r.groupID)
+}
+
 func (r *consumerGroupRunner) Error() <-chan error {
 	return r.errChan
 }
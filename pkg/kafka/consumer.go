@@ -1,14 +1,30 @@
 package kafka
 
 import (
+	"maps"
+	"sync"
+
 	"github.com/IBM/sarama"
 )
 
 type Consumer struct {
 	ready    chan bool
 	messages chan *MessageWithMarkFunc
+
+	claimsMu sync.RWMutex
+	claims   map[string][]int32
+
+	// onClaim is called when a partition claim starts.
+	onClaim func(topic string, partition int32)
+
+	// resolveOffset turns the sarama.OffsetOldest/OffsetNewest placeholder into the actual offset.
+	resolveOffset func(topic string, partition int32, offset int64) (int64, error)
+
+	stats *statsCollector
 }
 
+// MessageWithMarkFunc is a received message. Mark must be called once the message is processed,
+// the partition offset is committed only when all previous messages of the partition are marked too.
 type MessageWithMarkFunc struct {
 	Message *sarama.ConsumerMessage
 	Mark    func()
@@ -18,11 +34,16 @@ func NewConsumer(bufferSize int) *Consumer {
 	return &Consumer{
 		ready:    make(chan bool),
 		messages: make(chan *MessageWithMarkFunc, bufferSize),
+		stats:    newStatsCollector(),
 	}
 }
 
 // Setup is run at the beginning of a new session, before ConsumeClaim.
-func (c *Consumer) Setup(sarama.ConsumerGroupSession) error {
+func (c *Consumer) Setup(session sarama.ConsumerGroupSession) error {
+	c.claimsMu.Lock()
+	c.claims = session.Claims()
+	c.claimsMu.Unlock()
+
 	// Mark the consumer as ready
 	close(c.ready)
 
@@ -42,6 +63,17 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 	// Do not move the code below to a goroutine.
 	// The `ConsumeClaim` itself is called within a goroutine, see:
 	// https://github.com/IBM/sarama/blob/main/consumer_group.go#L27-L29
+	if c.onClaim != nil {
+		c.onClaim(claim.Topic(), claim.Partition())
+	}
+
+	tracker := newOffsetTracker(func(offset int64) {
+		session.MarkOffset(claim.Topic(), claim.Partition(), offset, "")
+	})
+
+	c.stats.addClaim(claim, tracker, c.initialOffset(claim))
+	defer c.stats.removeClaim(claim)
+
 	for {
 		select {
 		case message, ok := <-claim.Messages():
@@ -52,11 +84,15 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 			// If the channel is full, we wait until the space is free.
 			msg := message // copy value
 
+			tracker.add(msg.Offset)
+			c.stats.messageReceived()
+
 			select {
 			case c.messages <- &MessageWithMarkFunc{
 				Message: msg,
 				Mark: func() {
-					session.MarkMessage(msg, "")
+					tracker.complete(msg.Offset)
+					c.stats.messageProcessed()
 				},
 			}:
 			case <-session.Context().Done():
@@ -68,6 +104,34 @@ func (c *Consumer) ConsumeClaim(session sarama.ConsumerGroupSession, claim saram
 	}
 }
 
+// initialOffset returns the offset the claim starts from. Without a committed offset the claim
+// starts from a placeholder, which is resolved, so that the lag of the backlog is reported.
+func (c *Consumer) initialOffset(claim sarama.ConsumerGroupClaim) int64 {
+	offset := claim.InitialOffset()
+	if offset >= 0 || c.resolveOffset == nil {
+		return offset
+	}
+
+	if resolved, err := c.resolveOffset(claim.Topic(), claim.Partition(), offset); err == nil {
+		return resolved
+	}
+
+	return offset
+}
+
+// Claims returns partitions assigned to the consumer in the current session.
+func (c *Consumer) Claims() map[string][]int32 {
+	c.claimsMu.RLock()
+	defer c.claimsMu.RUnlock()
+
+	claims := maps.Clone(c.claims)
+	if claims == nil {
+		claims = make(map[string][]int32)
+	}
+
+	return claims
+}
+
 // GetMessages returns the channel where all received messages from kafka are recorded.
 func (c *Consumer) GetMessages() <-chan *MessageWithMarkFunc {
 	return c.messages
@@ -22,6 +22,7 @@ type Option func(*sarama.Config)
 // Implementations should ensure messages are delivered according to configured options.
 type Producer interface {
 	PushMessage(ctx context.Context, key, value []byte) (partition int32, offset int64, err error)
+	PushMessageWithHeaders(ctx context.Context, key, value []byte, headers map[string]string) (partition int32, offset int64, err error)
 	Close() error
 }
 
@@ -32,4 +33,11 @@ type ConsumerGroupRunner interface {
 	Shutdown() error
 	Error() <-chan error
 	Info() <-chan string
+
+	// Pause and Resume take partitions by topic, an empty map means all partitions.
+	Pause(partitions map[string][]int32) error
+	Resume(partitions map[string][]int32) error
+	PauseState() PauseState
+
+	Stats() Stats
 }
@@ -0,0 +1,73 @@
+package kafka
+
+import (
+	"sync"
+)
+
+// offsetTracker keeps track of in-flight messages of a single claimed partition.
+// Messages may be completed in any order, but the offset is only marked up to the
+// highest offset below which every received message has been completed, so a restart
+// never skips a message that is still being processed.
+type offsetTracker struct {
+	mu      sync.Mutex
+	pending []int64 // received offsets in ascending order
+	done    map[int64]struct{}
+	marked  int64 // last marked offset, -1 if nothing is marked yet
+	mark    func(offset int64)
+}
+
+func newOffsetTracker(mark func(offset int64)) *offsetTracker {
+	return &offsetTracker{
+		done:   make(map[int64]struct{}),
+		marked: -1,
+		mark:   mark,
+	}
+}
+
+// add registers a received message. Offsets must be added in the order they are read from the claim.
+func (t *offsetTracker) add(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending = append(t.pending, offset)
+}
+
+// complete marks the message as processed and advances the marked offset if possible.
+func (t *offsetTracker) complete(offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.pending) == 0 || offset < t.pending[0] {
+		return
+	}
+
+	t.done[offset] = struct{}{}
+
+	last := int64(-1)
+
+	for len(t.pending) > 0 {
+		head := t.pending[0]
+		if _, ok := t.done[head]; !ok {
+			break
+		}
+
+		delete(t.done, head)
+
+		t.pending = t.pending[1:]
+		last = head
+	}
+
+	if last >= 0 {
+		// The committed offset is the offset of the next message to read.
+		t.marked = last + 1
+		t.mark(t.marked)
+	}
+}
+
+// progress returns the last marked offset (-1 if none) and the number of messages not completed yet.
+func (t *offsetTracker) progress() (marked int64, inFlight int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.marked, len(t.pending)
+}
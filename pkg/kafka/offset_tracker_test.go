@@ -0,0 +1,109 @@
+package kafka
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestOffsetTracker(t *testing.T) {
+	tests := []struct {
+		name     string
+		added    []int64
+		complete []int64
+		// marks are the offsets passed to mark, in order.
+		marks    []int64
+		marked   int64
+		inFlight int
+	}{
+		{
+			name:     "nothing completed",
+			added:    []int64{10, 11, 12},
+			marked:   -1,
+			inFlight: 3,
+		},
+		{
+			name:     "in order",
+			added:    []int64{10, 11, 12},
+			complete: []int64{10, 11, 12},
+			marks:    []int64{11, 12, 13},
+			marked:   13,
+		},
+		{
+			name:     "head completed last",
+			added:    []int64{10, 11, 12},
+			complete: []int64{12, 11, 10},
+			marks:    []int64{13},
+			marked:   13,
+		},
+		{
+			name:     "gap keeps the mark",
+			added:    []int64{10, 11, 12, 13},
+			complete: []int64{10, 12, 13},
+			marks:    []int64{11},
+			marked:   11,
+			inFlight: 3,
+		},
+		{
+			name:     "gap filled",
+			added:    []int64{10, 11, 12, 13},
+			complete: []int64{10, 12, 13, 11},
+			marks:    []int64{11, 14},
+			marked:   14,
+		},
+		{
+			name:     "offsets with holes",
+			added:    []int64{10, 15, 20},
+			complete: []int64{15, 10},
+			marks:    []int64{16},
+			marked:   16,
+			inFlight: 1,
+		},
+		{
+			name:     "completed twice",
+			added:    []int64{10, 11},
+			complete: []int64{10, 10, 11},
+			marks:    []int64{11, 12},
+			marked:   12,
+		},
+		{
+			name:     "unknown offset below the pending ones",
+			added:    []int64{10, 11},
+			complete: []int64{5, 10},
+			marks:    []int64{11},
+			marked:   11,
+			inFlight: 1,
+		},
+		{
+			name:     "nothing pending",
+			complete: []int64{10},
+			marked:   -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var marks []int64
+
+			tracker := newOffsetTracker(func(offset int64) {
+				marks = append(marks, offset)
+			})
+
+			for _, offset := range tt.added {
+				tracker.add(offset)
+			}
+
+			for _, offset := range tt.complete {
+				tracker.complete(offset)
+			}
+
+			if !slices.Equal(marks, tt.marks) {
+				t.Errorf("marks = %v, want %v", marks, tt.marks)
+			}
+
+			marked, inFlight := tracker.progress()
+			if marked != tt.marked || inFlight != tt.inFlight {
+				t.Errorf("progress() = (%d, %d), want (%d, %d)", marked, inFlight, tt.marked, tt.inFlight)
+			}
+		})
+	}
+}
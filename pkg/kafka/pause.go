@@ -0,0 +1,142 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var ErrUnknownTopic = errors.New("topic is not consumed by the group")
+
+// PauseState describes which partitions are paused. If All is set, fetching is paused
+// for every partition assigned to this member and Partitions lists the assigned ones.
+type PauseState struct {
+	All        bool               `json:"all"`
+	Partitions map[string][]int32 `json:"partitions"`
+}
+
+// Pause suspends fetching from the given partitions, or from all assigned partitions if
+// partitions is empty. The group membership is kept, so no rebalance is triggered.
+// Messages that are already fetched are still delivered.
+func (r *consumerGroupRunner) Pause(partitions map[string][]int32) error {
+	if err := r.checkTopics(partitions); err != nil {
+		return err
+	}
+
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+
+	if len(partitions) == 0 {
+		r.consumptionIsPaused = true
+		r.pausedPartitions = make(map[string]map[int32]struct{})
+		r.client.PauseAll()
+
+		return nil
+	}
+
+	for topic, ps := range partitions {
+		if r.pausedPartitions[topic] == nil {
+			r.pausedPartitions[topic] = make(map[int32]struct{})
+		}
+
+		for _, p := range ps {
+			r.pausedPartitions[topic][p] = struct{}{}
+		}
+	}
+
+	r.client.Pause(partitions)
+
+	return nil
+}
+
+// Resume resumes fetching from the given partitions, or from all partitions if partitions is empty.
+func (r *consumerGroupRunner) Resume(partitions map[string][]int32) error {
+	if err := r.checkTopics(partitions); err != nil {
+		return err
+	}
+
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+
+	if len(partitions) == 0 {
+		r.consumptionIsPaused = false
+		r.pausedPartitions = make(map[string]map[int32]struct{})
+		r.client.ResumeAll()
+
+		return nil
+	}
+
+	// Resuming a part of the partitions after pausing everything leaves the rest paused individually.
+	if r.consumptionIsPaused {
+		r.consumptionIsPaused = false
+
+		for topic, ps := range r.consumer.Claims() {
+			r.pausedPartitions[topic] = make(map[int32]struct{}, len(ps))
+
+			for _, p := range ps {
+				r.pausedPartitions[topic][p] = struct{}{}
+			}
+		}
+	}
+
+	for topic, ps := range partitions {
+		for _, p := range ps {
+			delete(r.pausedPartitions[topic], p)
+		}
+
+		if len(r.pausedPartitions[topic]) == 0 {
+			delete(r.pausedPartitions, topic)
+		}
+	}
+
+	r.client.Resume(partitions)
+
+	return nil
+}
+
+func (r *consumerGroupRunner) PauseState() PauseState {
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+
+	state := PauseState{
+		All:        r.consumptionIsPaused,
+		Partitions: make(map[string][]int32),
+	}
+
+	if state.All {
+		state.Partitions = r.consumer.Claims()
+
+		return state
+	}
+
+	for topic, ps := range r.pausedPartitions {
+		for p := range ps {
+			state.Partitions[topic] = append(state.Partitions[topic], p)
+		}
+
+		slices.Sort(state.Partitions[topic])
+	}
+
+	return state
+}
+
+// reapplyPause is called when a partition is (re)assigned after a rebalance: a new partition
+// consumer is not paused, so the pause requested before the rebalance is applied again.
+func (r *consumerGroupRunner) reapplyPause(topic string, partition int32) {
+	r.pauseMu.Lock()
+	defer r.pauseMu.Unlock()
+
+	if _, ok := r.pausedPartitions[topic][partition]; ok || r.consumptionIsPaused {
+		r.client.Pause(map[string][]int32{topic: {partition}})
+	}
+}
+
+func (r *consumerGroupRunner) checkTopics(partitions map[string][]int32) error {
+	for topic := range partitions {
+		if !slices.Contains(r.topics, topic) {
+			return fmt.Errorf("%w: %q", ErrUnknownTopic, topic)
+		}
+	}
+
+	return nil
+}
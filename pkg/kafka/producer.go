@@ -115,6 +115,11 @@ func NewProducer(brokers []string, topic string, opts ...Option) (Producer, erro
 }
 
 func (p *producer) PushMessage(ctx context.Context, key, value []byte) (partition int32, offset int64, err error) {
+	return p.PushMessageWithHeaders(ctx, key, value, nil)
+}
+
+// PushMessageWithHeaders publishes a message with the given record headers attached.
+func (p *producer) PushMessageWithHeaders(ctx context.Context, key, value []byte, headers map[string]string) (partition int32, offset int64, err error) {
 	msg := &sarama.ProducerMessage{
 		Topic:     p.topic,
 		Key:       sarama.ByteEncoder(key),
@@ -122,6 +127,13 @@ func (p *producer) PushMessage(ctx context.Context, key, value []byte) (partitio
 		Timestamp: time.Now(),
 	}
 
+	for k, v := range headers {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{
+			Key:   []byte(k),
+			Value: []byte(v),
+		})
+	}
+
 	result := make(chan struct {
 		partition int32
 		offset    int64
@@ -0,0 +1,203 @@
+package kafka
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+const (
+	// throughputWindow is the period messages per second are averaged over.
+	throughputWindow = 10 * time.Second
+)
+
+// PartitionStats describes consumption progress of a single claimed partition.
+type PartitionStats struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	// HighWaterMark is the offset of the next message that will be produced to the partition.
+	HighWaterMark int64 `json:"high_water_mark"`
+	// MarkedOffset is the next offset to be committed, every message before it is processed.
+	MarkedOffset int64 `json:"marked_offset"`
+	Lag          int64 `json:"lag"`
+	InFlight     int   `json:"in_flight"`
+}
+
+// Stats describes consumption progress of the group member.
+type Stats struct {
+	GroupID           string           `json:"group_id"`
+	Partitions        []PartitionStats `json:"partitions"`
+	TotalLag          int64            `json:"total_lag"`
+	InFlight          int              `json:"in_flight"`
+	MessagesReceived  uint64           `json:"messages_received"`
+	MessagesProcessed uint64           `json:"messages_processed"`
+	MessagesPerSecond float64          `json:"messages_per_second"`
+}
+
+type claimStats struct {
+	claim   sarama.ConsumerGroupClaim
+	tracker *offsetTracker
+	// initial is the offset the claim started from, used until the first offset is marked.
+	initial int64
+}
+
+// statsCollector collects progress of active claims and counts processed messages.
+type statsCollector struct {
+	mu     sync.Mutex
+	claims map[string]map[int32]*claimStats
+
+	received  atomic.Uint64
+	processed atomic.Uint64
+
+	throughput *rateCounter
+}
+
+func newStatsCollector() *statsCollector {
+	return &statsCollector{
+		claims:     make(map[string]map[int32]*claimStats),
+		throughput: newRateCounter(throughputWindow),
+	}
+}
+
+func (s *statsCollector) addClaim(claim sarama.ConsumerGroupClaim, tracker *offsetTracker, initial int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.claims[claim.Topic()] == nil {
+		s.claims[claim.Topic()] = make(map[int32]*claimStats)
+	}
+
+	s.claims[claim.Topic()][claim.Partition()] = &claimStats{claim: claim, tracker: tracker, initial: initial}
+}
+
+func (s *statsCollector) removeClaim(claim sarama.ConsumerGroupClaim) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claims[claim.Topic()], claim.Partition())
+}
+
+func (s *statsCollector) messageReceived() {
+	s.received.Add(1)
+}
+
+func (s *statsCollector) messageProcessed() {
+	s.processed.Add(1)
+	s.throughput.add(1)
+}
+
+func (s *statsCollector) snapshot(groupID string) Stats {
+	stats := Stats{
+		GroupID:           groupID,
+		MessagesReceived:  s.received.Load(),
+		MessagesProcessed: s.processed.Load(),
+		MessagesPerSecond: s.throughput.rate(),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for topic, partitions := range s.claims {
+		for partition, c := range partitions {
+			hwm := c.claim.HighWaterMarkOffset()
+			marked, inFlight := c.tracker.progress()
+
+			if marked < 0 {
+				marked = c.initial
+			}
+
+			ps := PartitionStats{
+				Topic:         topic,
+				Partition:     partition,
+				HighWaterMark: hwm,
+				MarkedOffset:  marked,
+				InFlight:      inFlight,
+			}
+
+			// The initial offset stays a placeholder only if it could not be resolved.
+			if marked >= 0 && hwm > marked {
+				ps.Lag = hwm - marked
+			}
+
+			stats.Partitions = append(stats.Partitions, ps)
+			stats.TotalLag += ps.Lag
+			stats.InFlight += ps.InFlight
+		}
+	}
+
+	slices.SortFunc(stats.Partitions, func(a, b PartitionStats) int {
+		if a.Topic != b.Topic {
+			if a.Topic < b.Topic {
+				return -1
+			}
+
+			return 1
+		}
+
+		return int(a.Partition - b.Partition)
+	})
+
+	return stats
+}
+
+// rateCounter counts events in one second buckets over a sliding window.
+type rateCounter struct {
+	mu      sync.Mutex
+	buckets []uint64
+	last    int64 // unix second of the newest bucket
+}
+
+func newRateCounter(window time.Duration) *rateCounter {
+	return &rateCounter{
+		buckets: make([]uint64, int(window/time.Second)),
+	}
+}
+
+func (c *rateCounter) add(n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.advance(time.Now().Unix())
+	c.buckets[c.last%int64(len(c.buckets))] += n
+}
+
+// rate returns events per second over the full seconds of the window.
+func (c *rateCounter) rate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now().Unix()
+	c.advance(now)
+
+	var sum uint64
+
+	for i := range c.buckets {
+		// The current second is not complete yet.
+		if int64(i) == now%int64(len(c.buckets)) {
+			continue
+		}
+
+		sum += c.buckets[i]
+	}
+
+	return float64(sum) / float64(len(c.buckets)-1)
+}
+
+func (c *rateCounter) advance(now int64) {
+	size := int64(len(c.buckets))
+
+	if now-c.last >= size {
+		clear(c.buckets)
+		c.last = now
+
+		return
+	}
+
+	for c.last < now {
+		c.last++
+		c.buckets[c.last%size] = 0
+	}
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+const DefaultGRPCPort = 9090
+
+type GRPCServer interface {
+	Run() error
+	Shutdown() error
+}
+
+type grpcServer struct {
+	srv  *grpc.Server
+	addr string
+}
+
+// NewGRPCServer wraps srv, which must have all services registered, to listen on host:port.
+func NewGRPCServer(srv *grpc.Server, host string, port uint16) GRPCServer {
+	if host == "" {
+		host = DefaultHost
+	}
+
+	if port == 0 {
+		port = DefaultGRPCPort
+	}
+
+	return &grpcServer{
+		srv:  srv,
+		addr: net.JoinHostPort(host, strconv.Itoa(int(port))),
+	}
+}
+
+func (s *grpcServer) Run() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+	}
+
+	if err := s.srv.Serve(lis); err != nil {
+		return fmt.Errorf("failed to start gRPC server: %w", err)
+	}
+
+	return nil
+}
+
+// Shutdown waits for running calls to finish, open streams are cancelled after DefaultShutdownTimeout.
+func (s *grpcServer) Shutdown() error {
+	done := make(chan struct{})
+
+	go func() {
+		s.srv.GracefulStop()
+		close(done)
+	}()
+
+	timer := time.NewTimer(DefaultShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+	case <-timer.C:
+		s.srv.Stop()
+	}
+
+	return nil
+}